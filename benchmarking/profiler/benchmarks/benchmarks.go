@@ -108,6 +108,30 @@ func benchmarkSyncStdFloat64_500000(b *testing.B) {
 	options.RestoreDefaults()
 }
 
+func benchmarkGroupBySumFloat64_100000(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		df100k.GroupBySeries(keys100k).Sum()
+	}
+}
+
+func benchmarkGroupBySumFloat64_500000(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		df500k.GroupBySeries(keys500k).Sum()
+	}
+}
+
+func benchmarkGroupByMeanFloat64_100000(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		df100k.GroupBySeries(keys100k).Mean()
+	}
+}
+
+func benchmarkGroupByMeanFloat64_500000(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		df500k.GroupBySeries(keys500k).Mean()
+	}
+}
+
 func benchmarkReadSumFloat64_100000(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		df, err := pd.ReadCSV(getPath("100k"), pd.ReadOptions{HeaderRows: 1})
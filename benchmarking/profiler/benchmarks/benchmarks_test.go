@@ -35,3 +35,21 @@ func BenchmarkMath(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkGroupBy(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		fn   func(b *testing.B)
+	}{
+		{"100k groupby sum 1 column", benchmarkGroupBySumFloat64_100000},
+		{"100k groupby mean 1 column", benchmarkGroupByMeanFloat64_100000},
+		{"500k groupby sum 2 columns", benchmarkGroupBySumFloat64_500000},
+		{"500k groupby mean 2 columns", benchmarkGroupByMeanFloat64_500000},
+	}
+	ReadData()
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			bm.fn(b)
+		})
+	}
+}
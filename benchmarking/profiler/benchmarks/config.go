@@ -11,6 +11,7 @@ import (
 
 	"github.com/ptiger10/pd"
 	"github.com/ptiger10/pd/dataframe"
+	"github.com/ptiger10/pd/series"
 )
 
 // Descriptions of the benchmarking tests
@@ -25,6 +26,8 @@ var Descriptions = map[string]desc{
 	"readCSVSum10x": {7, "Read CSV, sum 10 cols individually"},
 	"sum2":          {8, "Sum two columns"},
 	"mean2":         {9, "Mean of two columns"},
+	"groupbySum":    {10, "Group by 100 keys, sum each group"},
+	"groupbyMean":   {11, "Group by 100 keys, mean of each group"},
 }
 
 // SampleSizes is all the potential sample sizes and the order in which they should appear in the comparison table.
@@ -38,6 +41,20 @@ var df100k *dataframe.DataFrame
 var df100k10x *dataframe.DataFrame
 var df500k *dataframe.DataFrame
 var df5m *dataframe.DataFrame
+var keys100k *series.Series
+var keys500k *series.Series
+
+// numGroupKeys is the number of distinct keys in the groupby benchmarks
+const numGroupKeys = 100
+
+// groupKeys returns n keys that cycle through numGroupKeys values, for grouping rows of equal length.
+func groupKeys(n int) *series.Series {
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = i % numGroupKeys
+	}
+	return series.MustNew(keys, series.Config{Name: "key"})
+}
 
 func read100k() {
 	var err error
@@ -82,6 +99,13 @@ func read100k() {
 		log.Fatalf("profiler/config.go: reading in test data: df.Std() got %v, want %v", got, want)
 	}
 
+	keys100k = groupKeys(df100k.Len())
+	got = math.Round(df100k.GroupBySeries(keys100k).Sum().Sum().At(0).(float64)*100) / 100
+	want = 50408.63
+	if got != want {
+		log.Fatalf("profiler/config.go: reading in test data: df.GroupBySeries().Sum() got %v, want %v", got, want)
+	}
+
 }
 
 func read100k10x() {
@@ -116,6 +140,13 @@ func read500k() {
 	if got != want {
 		log.Fatalf("profiler/config.go: reading in test data: df.Mean() got %v, want %v", got, want)
 	}
+
+	keys500k = groupKeys(df500k.Len())
+	got = math.Round(df500k.GroupBySeries(keys500k).Sum().Sum().At(0).(float64)*100) / 100
+	want = 130598.19
+	if got != want {
+		log.Fatalf("profiler/config.go: reading in test data: df.GroupBySeries().Sum() got %v, want %v", got, want)
+	}
 }
 
 func read5m() {
@@ -31,10 +31,14 @@ func RunGoProfiler() Results {
 			"max":  ProfileGo(benchmarkMaxFloat64_100000),
 			"std":  ProfileGo(benchmarkStdFloat64_100000),
 			// "readCSVSum": ProfileGo(benchmarkReadSumFloat64_100000),
+			"groupbySum":  ProfileGo(benchmarkGroupBySumFloat64_100000),
+			"groupbyMean": ProfileGo(benchmarkGroupByMeanFloat64_100000),
 		},
 		"500k": {
 			"sum2": ProfileGo(benchmarkSumFloat64_500000),
 			// 	"mean2": ProfileGo(benchmarkMeanFloat64_500000),
+			"groupbySum":  ProfileGo(benchmarkGroupBySumFloat64_500000),
+			"groupbyMean": ProfileGo(benchmarkGroupByMeanFloat64_500000),
 		},
 		// "5m": {
 		// 	"sum": ProfileGo(benchmarkSumFloat64_5m),
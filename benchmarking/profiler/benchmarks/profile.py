@@ -17,10 +17,14 @@ def main():
             "max": maxTest(),
             "std": stdTest(),
             # "readCSVSum": readCSVSumTest(),
+            "groupbySum": groupbySumTest(),
+            "groupbyMean": groupbyMeanTest(),
             },
         "500k": {
             "sum2": sumTest500(),
         #     "mean2": meanTest500(),
+            "groupbySum": groupbySumTest500(),
+            "groupbyMean": groupbyMeanTest500(),
         },
         # "5m": {
         #     "sum": sumTest5m(),
@@ -131,6 +135,34 @@ def stdTest():
     assert round(s.iloc[0], 2) == 0.29
 
 
+# number of distinct keys in the groupby tests, cycling through the rows
+num_group_keys = 100
+keys100 = [i % num_group_keys for i in range(len(df100))]
+keys500 = [i % num_group_keys for i in range(len(df500))]
+
+
+@timer(100)
+def groupbySumTest():
+    df = df100.groupby(keys100).sum()
+    assert round(df.sum().iloc[0], 2) == 50408.63
+
+
+@timer(100)
+def groupbyMeanTest():
+    df100.groupby(keys100).mean()
+
+
+@timer(20)
+def groupbySumTest500():
+    df = df500.groupby(keys500).sum()
+    assert round(df.sum().iloc[0], 2) == 130598.19
+
+
+@timer(20)
+def groupbyMeanTest500():
+    df500.groupby(keys500).mean()
+
+
 @timer(100)
 def medianTest():
     s = df100.median()
@@ -157,3 +157,29 @@ func (df *DataFrame) hydrateSeries(col int) *series.Series {
 	return series.FromInternalComponents(
 		df.vals[col], df.index, df.cols.Name(col))
 }
+
+// subsetSeries returns a Series with the values and index labels of a column at the specified row positions,
+// without copying the rest of the column.
+func (df *DataFrame) subsetSeries(col int, rowPositions []int) *series.Series {
+	container := values.Container{Values: df.vals[col].Values.Subset(rowPositions), DataType: df.vals[col].DataType}
+	return series.FromInternalComponents(container, df.subsetIndex(rowPositions), df.cols.Name(col))
+}
+
+// subsetFrame returns a DataFrame with the values and index labels at the specified row positions,
+// without copying the rest of df.
+func (df *DataFrame) subsetFrame(rowPositions []int) *DataFrame {
+	vals := make([]values.Container, df.NumCols())
+	for m := range df.vals {
+		vals[m] = values.Container{Values: df.vals[m].Values.Subset(rowPositions), DataType: df.vals[m].DataType}
+	}
+	return newFromComponents(vals, df.subsetIndex(rowPositions), df.cols.Copy(), df.name)
+}
+
+// subsetIndex returns a new Index with the labels at rowPositions, which must be valid.
+func (df *DataFrame) subsetIndex(rowPositions []int) index.Index {
+	levels := make([]index.Level, df.IndexLevels())
+	for j, lvl := range df.index.Levels {
+		levels[j] = index.Level{Labels: lvl.Labels.Subset(rowPositions), DataType: lvl.DataType, Name: lvl.Name, NeedsRefresh: true}
+	}
+	return index.New(levels...)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ptiger10/pd/internal/index"
 	"github.com/ptiger10/pd/internal/values"
 	"github.com/ptiger10/pd/options"
+	"github.com/ptiger10/pd/series"
 )
 
 // A DataFrame is a 2D collection of one or more Series with a shared index and associated columns.
@@ -89,13 +90,171 @@ type Config struct {
 	Manual          bool
 }
 
+// MergeOptions customizes DataFrame.Merge.
+//
+// On is the labels in column level 0 of the key columns, which must exist in both DataFrames.
+//
+// How is the type of merge: "inner" (default), "left", "right" or "outer".
+//
+// Suffixes are appended to the labels of non-key columns that exist in both DataFrames (default: "_x" and "_y").
+//
+// If Indicator is true, a "_merge" column records whether each row came from "left_only", "right_only" or "both".
+// Merge returns an error if the result would already have a column labeled "_merge".
+//
+// Validate checks the uniqueness of the keys before merging: "one_to_one", "one_to_many", "many_to_one",
+// or "" (default) for no check.
+type MergeOptions struct {
+	On        []string
+	How       string
+	Suffixes  [2]string
+	Indicator bool
+	Validate  string
+}
+
+// CompareOptions customizes DataFrame.Compare.
+//
+// Tolerance is the largest absolute difference between two numeric values that are considered equal.
+//
+// KeyColumns are the labels in column level 0 of the columns that identify each row, which must be unique in both DataFrames.
+// If empty, rows are matched by their index labels instead.
+type CompareOptions struct {
+	Tolerance  float64
+	KeyColumns []string
+}
+
+// PivotOptions customizes DataFrame.PivotTable.
+// Index, Columns and Values are labels in column level 0.
+//
+// Index is the columns whose unique values become the index levels of the result, and must not be empty.
+//
+// Columns is the columns whose unique values become the innermost column levels of the result.
+//
+// Values is the columns to aggregate (default: every column not in Index or Columns).
+//
+// AggFuncs maps each value column to its aggregations (default: series.AggMean).
+//
+// FillValue replaces null results, including combinations of labels that do not exist in the DataFrame.
+//
+// If Margins is true, a row and a column labeled "All" contain the aggregations across every column and every row, respectively.
+type PivotOptions struct {
+	Index     []string
+	Columns   []string
+	Values    []string
+	AggFuncs  map[string][]series.Agg
+	FillValue interface{}
+	Margins   bool
+}
+
+// CrosstabOptions customizes Crosstab.
+//
+// Values are aggregated with AggFunc for each combination of row and column labels.
+// If Values is nil, Crosstab counts the occurrences of each combination instead, and missing combinations are 0.
+// Values and AggFunc must be supplied together.
+//
+// Normalize divides each cell by the total of "all" cells, of its row ("index") or of its column ("columns").
+// The default ("") does not normalize.
+//
+// If Margins is true, a row and a column labeled "All" contain the totals across every column and every row, respectively.
+//
+// If DropNA is true, null row and column labels are excluded. Otherwise they form their own row or column.
+type CrosstabOptions struct {
+	Values    *series.Series
+	AggFunc   series.Agg
+	Normalize string
+	Margins   bool
+	DropNA    bool
+}
+
+// GroupByOptions customizes DataFrame.GroupByColsWithOptions.
+// If KeepKeys is true, the key columns are copied into the index instead of moved, so they remain columns in every group.
+// Every column may then be a key column.
+type GroupByOptions struct {
+	KeepKeys bool
+}
+
+// EachOptions customizes Grouping.Each.
+//
+// Sort is the order in which groups are visited: "first" (default) for their order of first appearance, as in Grouping.Groups(),
+// "sorted" for the alphabetical order of their labels, as in Grouping.SortedGroups(),
+// or "natural" for the typed order of their keys, level by level.
+// In natural order, numeric labels are sorted by value, DateTime labels chronologically, false before true, and null labels last.
+// Labels of different types are sorted by their String().
+type EachOptions struct {
+	Sort string
+}
+
+// AggOptions customizes Grouping.Agg.
+// By default, each result column is labeled column + "_" + aggregation name (e.g., "revenue_sum").
+// If MultiLevel is true, result columns are labeled with two column levels instead: column and aggregation name.
+type AggOptions struct {
+	MultiLevel bool
+}
+
+// DummyOptions customizes GetDummies.
+//
+// Prefix maps the name of an encoded column to the prefix of its indicator columns (default: the column name).
+// Indicator columns are labeled prefix + PrefixSep + category, where PrefixSep defaults to "_".
+//
+// If DropFirst is true, the indicator column for the first category is dropped.
+// If DummyNA is true, an additional indicator column records null values, labeled with options.GetDisplayStringNullFiller().
+//
+// DataType is the DataType of the indicator columns: options.Bool (default) or options.Int64.
+//
+// If MultiLevel is true, indicator columns are labeled with two column levels (prefix and category) instead of joined labels,
+// and columns that are not encoded have an empty category label.
+type DummyOptions struct {
+	Prefix     map[string]string
+	PrefixSep  string
+	DropFirst  bool
+	DummyNA    bool
+	DataType   options.DataType
+	MultiLevel bool
+}
+
+// FromDummiesOptions customizes FromDummies.
+//
+// PrefixSep separates the prefix from the category in the labels of single-level indicator columns (default: "_").
+//
+// Prefixes is the prefixes of the single-level indicator columns to decode. By default, FromDummies decodes every group of
+// at least two Bool or 0/1 columns with a shared prefix in which each row has at most one indicator set.
+//
+// DefaultCategory maps a prefix to the category of rows in which no indicator is set (e.g., the category dropped by DropFirst).
+// Without a default, those rows are null.
+type FromDummiesOptions struct {
+	PrefixSep       string
+	Prefixes        []string
+	DefaultCategory map[string]string
+}
+
+// A CompareSummary describes the rows, columns and DataTypes that differ between two DataFrames.
+// Rows are identified by their index labels, or by their key values if KeyColumns are supplied.
+// Columns are identified by their names, with multiple levels joined by options.GetMultiColNameSeparator().
+// DataTypeChanges maps each column to its DataType in self and other.
+type CompareSummary struct {
+	AddedRows       [][]interface{}
+	RemovedRows     [][]interface{}
+	AddedColumns    []string
+	RemovedColumns  []string
+	DataTypeChanges map[string][2]options.DataType
+}
+
 // A Grouping returns a collection of index labels with mutually exclusive integer positions.
 type Grouping struct {
 	df     *DataFrame
 	groups map[string]*group
 	err    bool
+	// grouped DataFrame, before any index levels are dropped or replaced by columns
+	source *DataFrame
+	// group code of every row, numbered in order of first appearance
+	codes []int
+	// group ids, in code order
+	order []string
 }
 
+// A GroupKey identifies a group in a Grouping by its typed label in each grouped index level, in level order.
+// It is the same type as series.GroupKey.
+type GroupKey = series.GroupKey
+
 func (g Grouping) String() string {
 	printer := fmt.Sprintf("{DataFrame Grouping | NumGroups: %v, Groups: [%v]}\n", len(g.groups), strings.Join(g.Groups(), ", "))
 	return printer
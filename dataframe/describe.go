@@ -5,9 +5,11 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/ptiger10/pd/internal/index"
 	"github.com/ptiger10/pd/internal/values"
 	"github.com/ptiger10/pd/options"
 	"github.com/ptiger10/pd/series"
@@ -291,6 +293,228 @@ func Equal(df, df2 *DataFrame) bool {
 	return true
 }
 
+// Compare returns the cells that differ between df and other, plus a summary of the rows, columns and DataTypes that differ.
+//
+// Rows are matched by their index labels, or by their values in config.KeyColumns, regardless of row order.
+// Columns are matched by name. Only cells in rows and columns present in both DataFrames are compared:
+// two nulls are equal, and numeric values are equal if they differ by no more than config.Tolerance.
+//
+// The returned DataFrame has one row per differing cell, indexed by the row labels (or key values) plus a "column" level,
+// with the value in df under "self" and the value in other under "other". If no cells differ, it is empty.
+func (df *DataFrame) Compare(other *DataFrame, config ...CompareOptions) (*DataFrame, CompareSummary, error) {
+	var summary CompareSummary
+	var tmp CompareOptions
+	if len(config) > 1 {
+		return newEmptyDataFrame(), summary, fmt.Errorf(
+			"DataFrame.Compare(): can supply at most one CompareOptions (%d > 1)", len(config))
+	} else if len(config) == 1 {
+		tmp = config[0]
+	}
+	if tmp.Tolerance < 0 {
+		return newEmptyDataFrame(), summary, fmt.Errorf("DataFrame.Compare(): Tolerance must be non-negative, not %v", tmp.Tolerance)
+	}
+
+	// rows
+	var selfKeys, otherKeys []int
+	var selfToOther, otherToSelf []int
+	if len(tmp.KeyColumns) > 0 {
+		var err error
+		selfKeys, err = df.colPositions(tmp.KeyColumns)
+		if err != nil {
+			return newEmptyDataFrame(), summary, fmt.Errorf("DataFrame.Compare(): self: %v", err)
+		}
+		otherKeys, err = other.colPositions(tmp.KeyColumns)
+		if err != nil {
+			return newEmptyDataFrame(), summary, fmt.Errorf("DataFrame.Compare(): other: %v", err)
+		}
+		selfTable, selfOrder := df.keyTable(selfKeys)
+		otherTable, otherOrder := other.keyTable(otherKeys)
+		if dupes := df.duplicateKeys(selfKeys, selfTable, selfOrder); len(dupes) > 0 {
+			return newEmptyDataFrame(), summary, fmt.Errorf(
+				"DataFrame.Compare(): keys %v are not unique in self: duplicate keys: %v", tmp.KeyColumns, dupes)
+		}
+		if dupes := other.duplicateKeys(otherKeys, otherTable, otherOrder); len(dupes) > 0 {
+			return newEmptyDataFrame(), summary, fmt.Errorf(
+				"DataFrame.Compare(): keys %v are not unique in other: duplicate keys: %v", tmp.KeyColumns, dupes)
+		}
+		selfToOther = df.matchKeys(selfKeys, otherTable)
+		otherToSelf = other.matchKeys(otherKeys, selfTable)
+	} else {
+		var err error
+		selfToOther, err = df.index.LookupPositions(other.index, "error")
+		if err != nil {
+			return newEmptyDataFrame(), summary, fmt.Errorf("DataFrame.Compare(): other: %v", err)
+		}
+		otherToSelf, err = other.index.LookupPositions(df.index, "error")
+		if err != nil {
+			return newEmptyDataFrame(), summary, fmt.Errorf("DataFrame.Compare(): self: %v", err)
+		}
+	}
+	rowLabels := func(d *DataFrame, keys []int, row int) []interface{} {
+		if keys == nil {
+			return d.index.Elements(row).Labels
+		}
+		return d.rowValues(row, keys)
+	}
+	for i, j := range selfToOther {
+		if j == -1 {
+			summary.RemovedRows = append(summary.RemovedRows, rowLabels(df, selfKeys, i))
+		}
+	}
+	for j, i := range otherToSelf {
+		if i == -1 {
+			summary.AddedRows = append(summary.AddedRows, rowLabels(other, otherKeys, j))
+		}
+	}
+
+	// columns
+	selfNames, otherNames := df.cols.Names(), other.cols.Names()
+	selfCols, err := nameTable(selfNames)
+	if err != nil {
+		return newEmptyDataFrame(), summary, fmt.Errorf("DataFrame.Compare(): self: %v", err)
+	}
+	otherCols, err := nameTable(otherNames)
+	if err != nil {
+		return newEmptyDataFrame(), summary, fmt.Errorf("DataFrame.Compare(): other: %v", err)
+	}
+	var compared []string
+	var equal []func(i, j int) bool
+	var selfPositions, otherPositions []int
+	for m, name := range selfNames {
+		k, ok := otherCols[name]
+		if !ok {
+			summary.RemovedColumns = append(summary.RemovedColumns, name)
+			continue
+		}
+		if df.vals[m].DataType != other.vals[k].DataType {
+			if summary.DataTypeChanges == nil {
+				summary.DataTypeChanges = make(map[string][2]options.DataType)
+			}
+			summary.DataTypeChanges[name] = [2]options.DataType{df.vals[m].DataType, other.vals[k].DataType}
+		}
+		isKey := false
+		for _, pos := range selfKeys {
+			isKey = isKey || pos == m
+		}
+		if !isKey {
+			compared = append(compared, name)
+			equal = append(equal, cellsEqual(df.vals[m], other.vals[k], tmp.Tolerance))
+			selfPositions = append(selfPositions, m)
+			otherPositions = append(otherPositions, k)
+		}
+	}
+	for _, name := range otherNames {
+		if _, ok := selfCols[name]; !ok {
+			summary.AddedColumns = append(summary.AddedColumns, name)
+		}
+	}
+
+	// cells
+	var diffRows []int
+	var diffCols []string
+	var selfVals, otherVals []interface{}
+	cellValue := func(container values.Container, row int) interface{} {
+		if container.Values.Null(row) {
+			return nil
+		}
+		return container.Values.Value(row)
+	}
+	for i, j := range selfToOther {
+		if j == -1 {
+			continue
+		}
+		for c := range compared {
+			if equal[c](i, j) {
+				continue
+			}
+			diffRows = append(diffRows, i)
+			diffCols = append(diffCols, compared[c])
+			selfVals = append(selfVals, cellValue(df.vals[selfPositions[c]], i))
+			otherVals = append(otherVals, cellValue(other.vals[otherPositions[c]], j))
+		}
+	}
+	if len(diffRows) == 0 {
+		return newEmptyDataFrame(), summary, nil
+	}
+	var levels []index.Level
+	if selfKeys != nil {
+		for k, m := range selfKeys {
+			levels = append(levels, index.Level{Labels: df.vals[m].Values.Subset(diffRows), DataType: df.vals[m].DataType,
+				Name: tmp.KeyColumns[k], NeedsRefresh: true})
+		}
+	} else {
+		for _, lvl := range df.index.Levels {
+			levels = append(levels, index.Level{Labels: lvl.Labels.Subset(diffRows), DataType: lvl.DataType,
+				Name: lvl.Name, NeedsRefresh: true})
+		}
+	}
+	levels = append(levels, index.MustNewLevel(diffCols, "column"))
+	vals := []values.Container{values.ScalarSliceFactory(selfVals), values.ScalarSliceFactory(otherVals)}
+	cols := index.NewColumns(index.NewColLevel([]string{"self", "other"}, ""))
+	return newFromComponents(vals, index.New(levels...), cols, df.name), summary, nil
+}
+
+// matchKeys returns the first row in table with the same key as every row of df, or -1 if there is none.
+func (df *DataFrame) matchKeys(cols []int, table map[string][]int) []int {
+	ret := make([]int, df.Len())
+	for i := range ret {
+		ret[i] = -1
+		if rows, ok := table[df.rowKey(i, cols)]; ok {
+			ret[i] = rows[0]
+		}
+	}
+	return ret
+}
+
+// nameTable maps every column name to its position and returns an error if any name is duplicated.
+func nameTable(names []string) (map[string]int, error) {
+	table := make(map[string]int, len(names))
+	for m, name := range names {
+		if _, ok := table[name]; ok {
+			return nil, fmt.Errorf("duplicate column name %q", name)
+		}
+		table[name] = m
+	}
+	return table, nil
+}
+
+// cellsEqual returns a function reporting whether the value at row i of a equals the value at row j of b.
+// Numeric values are compared within tolerance, and other values by type and value.
+func cellsEqual(a, b values.Container, tolerance float64) func(i, j int) bool {
+	isNumeric := func(dataType options.DataType) bool {
+		return dataType == options.Float64 || dataType == options.Int64
+	}
+	nulls := func(i, j int) (bool, bool) {
+		nullA, nullB := a.Values.Null(i), b.Values.Null(j)
+		return nullA || nullB, nullA == nullB
+	}
+	if a.DataType == options.Int64 && b.DataType == options.Int64 {
+		intsA, intsB := a.Values.Vals().([]int64), b.Values.Vals().([]int64)
+		return func(i, j int) bool {
+			if null, equal := nulls(i, j); null {
+				return equal
+			}
+			return intsA[i] == intsB[j] || math.Abs(float64(intsA[i])-float64(intsB[j])) <= tolerance
+		}
+	}
+	if isNumeric(a.DataType) && isNumeric(b.DataType) {
+		floatsA := a.Values.ToFloat64().Vals().([]float64)
+		floatsB := b.Values.ToFloat64().Vals().([]float64)
+		return func(i, j int) bool {
+			if null, equal := nulls(i, j); null {
+				return equal
+			}
+			return floatsA[i] == floatsB[j] || math.Abs(floatsA[i]-floatsB[j]) <= tolerance
+		}
+	}
+	return func(i, j int) bool {
+		if null, equal := nulls(i, j); null {
+			return equal
+		}
+		return values.Key(a.Values.Value(i)) == values.Key(b.Values.Value(j))
+	}
+}
+
 // DataTypes returns the DataTypes of the Series in the DataFrame.
 func (df *DataFrame) DataTypes() *series.Series {
 	if len(df.vals) == 0 {
@@ -522,6 +522,7 @@ func ExampleInPlace_method_list() {
 	// ToInt64
 	// ToInterface
 	// ToString
+	// Update
 }
 
 func ExampleIndex_valid_printer() {
@@ -122,6 +122,74 @@ func TestEqual(t *testing.T) {
 	}
 }
 
+func TestDataFrame_Compare(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3}, []string{"x", "", "z"}, []float64{1, 2, 3}},
+		Config{Col: []string{"A", "B", "C"}, Index: []string{"a", "b", "c"}})
+	other := MustNew([]interface{}{[]int{3, 10, 5}, []string{"z", "x", "w"}, []float64{3.05, 1, 9}, []string{"foo", "bar", "baz"}},
+		Config{Col: []string{"A", "B", "C", "D"}, Index: []string{"c", "a", "d"}})
+	subset, _ := df.SubsetColumns([]int{0, 1})
+	keyed := MustNew([]interface{}{[]int{1, 2}, []int{10, 20}}, Config{Col: []string{"id", "val"}})
+	keyedOther := MustNew([]interface{}{[]int{2, 1}, []float64{20, 11.5}}, Config{Col: []string{"id", "val"}})
+	type args struct {
+		other  *DataFrame
+		config CompareOptions
+	}
+	tests := []struct {
+		name        string
+		input       *DataFrame
+		args        args
+		want        *DataFrame
+		wantSummary CompareSummary
+		wantErr     bool
+	}{
+		{name: "index", input: df, args: args{other, CompareOptions{Tolerance: 0.1}},
+			want: MustNew([]interface{}{[]int{1}, []int{10}},
+				Config{Col: []string{"self", "other"}, MultiIndex: []interface{}{[]string{"a"}, []string{"A"}},
+					MultiIndexNames: []string{"", "column"}}),
+			wantSummary: CompareSummary{AddedRows: [][]interface{}{{"d"}}, RemovedRows: [][]interface{}{{"b"}},
+				AddedColumns: []string{"D"}},
+			wantErr: false},
+		{"index without tolerance", df, args{other, CompareOptions{}},
+			MustNew([]interface{}{[]float64{1, 3}, []float64{10, 3.05}},
+				Config{Col: []string{"self", "other"}, MultiIndex: []interface{}{[]string{"a", "c"}, []string{"A", "C"}},
+					MultiIndexNames: []string{"", "column"}}),
+			CompareSummary{AddedRows: [][]interface{}{{"d"}}, RemovedRows: [][]interface{}{{"b"}},
+				AddedColumns: []string{"D"}},
+			false},
+		{"keyed with different row order", keyed, args{keyedOther, CompareOptions{KeyColumns: []string{"id"}}},
+			MustNew([]interface{}{[]int{10}, []float64{11.5}},
+				Config{Col: []string{"self", "other"}, MultiIndex: []interface{}{[]int{1}, []string{"val"}},
+					MultiIndexNames: []string{"id", "column"}}),
+			CompareSummary{DataTypeChanges: map[string][2]options.DataType{"val": {options.Int64, options.Float64}}},
+			false},
+		{"no differences", df, args{df, CompareOptions{}}, newEmptyDataFrame(), CompareSummary{}, false},
+		{"removed column", df, args{subset, CompareOptions{}}, newEmptyDataFrame(),
+			CompareSummary{RemovedColumns: []string{"C"}}, false},
+		{"fail: duplicate keys", keyed, args{MustNew([]interface{}{[]int{1, 1}}, Config{Col: []string{"id"}}),
+			CompareOptions{KeyColumns: []string{"id"}}}, newEmptyDataFrame(), CompareSummary{}, true},
+		{"fail: missing key column", keyed, args{keyedOther, CompareOptions{KeyColumns: []string{"foo"}}},
+			newEmptyDataFrame(), CompareSummary{}, true},
+		{"fail: duplicate index labels", df, args{MustNew([]interface{}{[]int{1, 2}}, Config{Index: []string{"a", "a"}}),
+			CompareOptions{}}, newEmptyDataFrame(), CompareSummary{}, true},
+		{"fail: negative tolerance", df, args{df, CompareOptions{Tolerance: -1}},
+			newEmptyDataFrame(), CompareSummary{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotSummary, err := tt.input.Compare(tt.args.other, tt.args.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.Compare() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.Compare() got %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotSummary, tt.wantSummary) {
+				t.Errorf("DataFrame.Compare() summary got %#v, want %#v", gotSummary, tt.wantSummary)
+			}
+		})
+	}
+}
+
 func TestMaxColWidth(t *testing.T) {
 	type want struct {
 		colWidths       []int
@@ -0,0 +1,298 @@
+package dataframe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ptiger10/pd/internal/index"
+	"github.com/ptiger10/pd/internal/values"
+	"github.com/ptiger10/pd/options"
+)
+
+// GetDummies returns a new DataFrame in which the columns named cols are replaced by one indicator column per category.
+// If no cols are supplied, every string, bool and interface column is encoded.
+// Categories are the sorted unique values of each column (see Series.Unique), so their order is deterministic.
+// Columns that are not encoded keep their original order, followed by the indicator columns in the order of cols.
+// See DummyOptions for labeling and DataType options. Requires df to have a single column level.
+func (df *DataFrame) GetDummies(cols []string, config ...DummyOptions) (*DataFrame, error) {
+	tmp := DummyOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.GetDummies(): can supply at most one DummyOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	if tmp.PrefixSep == "" {
+		tmp.PrefixSep = "_"
+	}
+	if tmp.DataType == options.None {
+		tmp.DataType = options.Bool
+	}
+	if tmp.DataType != options.Bool && tmp.DataType != options.Int64 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.GetDummies(): DataType must be Bool or Int64, not %v", tmp.DataType)
+	}
+	if df.ColLevels() != 1 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.GetDummies(): must have a single column level")
+	}
+
+	var positions []int
+	if len(cols) > 0 {
+		var err error
+		positions, err = df.colPositions(cols)
+		if err != nil {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.GetDummies(): %v", err)
+		}
+	} else {
+		for m := 0; m < df.NumCols(); m++ {
+			switch df.vals[m].DataType {
+			case options.String, options.Bool, options.Interface:
+				positions = append(positions, m)
+			}
+		}
+	}
+	if len(positions) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.GetDummies(): no columns to encode")
+	}
+	encoded := make(map[int]bool)
+	for _, m := range positions {
+		encoded[m] = true
+	}
+
+	var vals []values.Container
+	var prefixes, categories []string
+	for m := 0; m < df.NumCols(); m++ {
+		if !encoded[m] {
+			vals = append(vals, df.vals[m].Copy())
+			prefixes = append(prefixes, df.cols.Levels[0].Labels[m])
+			categories = append(categories, "")
+		}
+	}
+
+	nullLabel := options.GetDisplayStringNullFiller()
+	for _, m := range positions {
+		name := df.cols.Levels[0].Labels[m]
+		prefix, ok := tmp.Prefix[name]
+		if !ok {
+			prefix = name
+		}
+		cats := df.hydrateSeries(m).Unique()
+		sort.Strings(cats)
+		if tmp.DropFirst && len(cats) > 0 {
+			cats = cats[1:]
+		}
+		if tmp.DummyNA {
+			cats = append(cats, nullLabel)
+		}
+		rowLabels := make([]string, df.Len())
+		for i := range rowLabels {
+			if df.vals[m].Values.Null(i) {
+				rowLabels[i] = nullLabel
+				continue
+			}
+			rowLabels[i] = fmt.Sprint(df.vals[m].Values.Value(i))
+		}
+		for _, cat := range cats {
+			vals = append(vals, indicator(rowLabels, cat, tmp.DataType))
+			prefixes = append(prefixes, prefix)
+			categories = append(categories, cat)
+		}
+	}
+
+	var newCols index.Columns
+	if tmp.MultiLevel {
+		newCols = index.NewColumns(
+			index.NewColLevel(prefixes, df.cols.Levels[0].Name),
+			index.NewColLevel(categories, ""))
+	} else {
+		labels := make([]string, len(prefixes))
+		for k := range prefixes {
+			labels[k] = prefixes[k]
+			if categories[k] != "" {
+				labels[k] += tmp.PrefixSep + categories[k]
+			}
+		}
+		newCols = index.NewColumns(index.NewColLevel(labels, df.cols.Levels[0].Name))
+	}
+	return newFromComponents(vals, df.index.Copy(), newCols, df.name), nil
+}
+
+// indicator returns a Bool or Int64 Container that is true (or 1) wherever labels equals category.
+func indicator(labels []string, category string, dataType options.DataType) values.Container {
+	if dataType == options.Int64 {
+		ret := make([]int64, len(labels))
+		for i := range labels {
+			if labels[i] == category {
+				ret[i] = 1
+			}
+		}
+		return values.MustCreateValuesFromInterface(ret)
+	}
+	ret := make([]bool, len(labels))
+	for i := range labels {
+		ret[i] = labels[i] == category
+	}
+	return values.MustCreateValuesFromInterface(ret)
+}
+
+// FromDummies reverses GetDummies and returns a new DataFrame in which each group of indicator columns
+// is replaced by a single string column of categories, named after the group's prefix.
+// If df has two column levels, level 0 is the prefix and level 1 is the category, and columns with an empty category are not indicators.
+// Otherwise, indicator columns are labeled prefix + config.PrefixSep + category, split at the last separator
+// so that prefixes may contain it (e.g. "unit_price_low" has prefix "unit_price").
+// If config.Prefixes is supplied, only columns with those prefixes are indicators.
+// If not, a column is an indicator only if it is Bool, or Int64 or Float64 of 0 and 1, and its group has at least two such columns
+// (or a DefaultCategory) with at most one set in each row, so that ordinary columns such as "is_member" are not decoded.
+// Indicators with two column levels or supplied prefixes must be Bool, Int64 or Float64. A null indicator is unset.
+// Columns that are not indicators keep their original order, followed by the decoded columns in order of first appearance.
+// A row in which no indicator is set has the prefix's default category (see FromDummiesOptions) or is null,
+// as is a row whose category is options.GetDisplayStringNullFiller().
+// Returns an error if more than one indicator is set in any row of a group with two column levels or a supplied prefix.
+func (df *DataFrame) FromDummies(config ...FromDummiesOptions) (*DataFrame, error) {
+	tmp := FromDummiesOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.FromDummies(): can supply at most one FromDummiesOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	if tmp.PrefixSep == "" {
+		tmp.PrefixSep = "_"
+	}
+	if df.ColLevels() != 1 && df.ColLevels() != 2 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.FromDummies(): must have one or two column levels")
+	}
+	explicit := df.ColLevels() == 2 || tmp.Prefixes != nil
+	supplied := make(map[string]bool)
+	for _, prefix := range tmp.Prefixes {
+		supplied[prefix] = true
+	}
+
+	// candidate indicator columns, grouped by prefix
+	var prefixes []string
+	groups := make(map[string][]int)
+	categories := make([]string, df.NumCols())
+	for m := 0; m < df.NumCols(); m++ {
+		var prefix, category string
+		if df.ColLevels() == 2 {
+			prefix, category = df.cols.Levels[0].Labels[m], df.cols.Levels[1].Labels[m]
+			if category == "" {
+				continue
+			}
+		} else {
+			label := df.cols.Levels[0].Labels[m]
+			sep := strings.LastIndex(label, tmp.PrefixSep)
+			if sep == -1 {
+				continue
+			}
+			prefix, category = label[:sep], label[sep+len(tmp.PrefixSep):]
+			if tmp.Prefixes != nil && !supplied[prefix] {
+				continue
+			}
+			if !explicit && !isIndicator(df.vals[m]) {
+				continue
+			}
+		}
+		if explicit {
+			switch df.vals[m].DataType {
+			case options.Bool, options.Int64, options.Float64:
+			default:
+				return newEmptyDataFrame(), fmt.Errorf(
+					"DataFrame.FromDummies(): indicator column %v must be Bool, Int64 or Float64, not %v", df.cols.Name(m), df.vals[m].DataType)
+			}
+		}
+		if _, ok := groups[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+		groups[prefix] = append(groups[prefix], m)
+		categories[m] = category
+	}
+
+	// decode each group, or leave it as ordinary columns if it is not a group of dummies
+	nullLabel := options.GetDisplayStringNullFiller()
+	decoded := make(map[int]bool)
+	var decodedVals []values.Container
+	var decodedLabels []string
+	for _, prefix := range prefixes {
+		_, hasDefault := tmp.DefaultCategory[prefix]
+		if !explicit && len(groups[prefix]) < 2 && !hasDefault {
+			continue
+		}
+		indicators := make([]values.Values, len(groups[prefix]))
+		for k, m := range groups[prefix] {
+			// ducks error because DataType is supported
+			indicators[k], _ = values.Convert(df.vals[m].Values, options.Bool)
+		}
+		labels := make([]string, df.Len())
+		isDummy := true
+		for i := range labels {
+			set := -1
+			for k := range indicators {
+				if indicators[k].Null(i) || !indicators[k].Value(i).(bool) {
+					continue
+				}
+				if set != -1 {
+					if explicit {
+						return newEmptyDataFrame(), fmt.Errorf(
+							"DataFrame.FromDummies(): row %d has more than one indicator set for %v", i, prefix)
+					}
+					isDummy = false
+					break
+				}
+				set = k
+			}
+			if !isDummy {
+				break
+			}
+			if set == -1 {
+				labels[i] = tmp.DefaultCategory[prefix]
+				continue
+			}
+			if category := categories[groups[prefix][set]]; category != nullLabel {
+				labels[i] = category
+			}
+		}
+		if !isDummy {
+			continue
+		}
+		for _, m := range groups[prefix] {
+			decoded[m] = true
+		}
+		decodedVals = append(decodedVals, values.MustCreateValuesFromInterface(labels))
+		decodedLabels = append(decodedLabels, prefix)
+	}
+
+	var vals []values.Container
+	var labels []string
+	for m := 0; m < df.NumCols(); m++ {
+		if decoded[m] {
+			continue
+		}
+		vals = append(vals, df.vals[m].Copy())
+		labels = append(labels, df.cols.Levels[0].Labels[m])
+	}
+	vals = append(vals, decodedVals...)
+	labels = append(labels, decodedLabels...)
+	if len(vals) == 0 {
+		return newEmptyDataFrame(), nil
+	}
+	cols := index.NewColumns(index.NewColLevel(labels, df.cols.Levels[0].Name))
+	return newFromComponents(vals, df.index.Copy(), cols, df.name), nil
+}
+
+// isIndicator returns true if container is Bool, or Int64 or Float64 with every non-null value 0 or 1.
+func isIndicator(container values.Container) bool {
+	switch container.DataType {
+	case options.Bool:
+		return true
+	case options.Int64, options.Float64:
+		for i, f := range container.Values.ToFloat64().Vals().([]float64) {
+			if !container.Values.Null(i) && f != 0 && f != 1 {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,168 @@
+package dataframe
+
+import (
+	"testing"
+
+	"github.com/ptiger10/pd/options"
+)
+
+func TestDataFrame_GetDummies(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"b", "a", ""}, []int{1, 2, 3}}, Config{Col: []string{"foo", "bar"}})
+	type args struct {
+		cols   []string
+		config []DummyOptions
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "default", args: args{nil, nil},
+			want: MustNew([]interface{}{[]int{1, 2, 3}, []bool{false, true, false}, []bool{true, false, false}},
+				Config{Col: []string{"bar", "foo_a", "foo_b"}}),
+			wantErr: false},
+		{"int64 with prefix", args{[]string{"foo"}, []DummyOptions{{Prefix: map[string]string{"foo": "f"}, PrefixSep: ".", DataType: options.Int64}}},
+			MustNew([]interface{}{[]int{1, 2, 3}, []int64{0, 1, 0}, []int64{1, 0, 0}}, Config{Col: []string{"bar", "f.a", "f.b"}}),
+			false},
+		{"drop first and dummy null", args{[]string{"foo"}, []DummyOptions{{DropFirst: true, DummyNA: true}}},
+			MustNew([]interface{}{[]int{1, 2, 3}, []bool{true, false, false}, []bool{false, false, true}},
+				Config{Col: []string{"bar", "foo_b", "foo_NaN"}}),
+			false},
+		{"multi level", args{[]string{"foo"}, []DummyOptions{{MultiLevel: true}}},
+			MustNew([]interface{}{[]int{1, 2, 3}, []bool{false, true, false}, []bool{true, false, false}},
+				Config{MultiCol: [][]string{{"bar", "foo", "foo"}, {"", "a", "b"}}}),
+			false},
+		{"numeric column", args{[]string{"bar"}, nil},
+			MustNew([]interface{}{[]string{"b", "a", ""}, []bool{true, false, false}, []bool{false, true, false}, []bool{false, false, true}},
+				Config{Col: []string{"foo", "bar_1", "bar_2", "bar_3"}}),
+			false},
+		{"fail: missing column", args{[]string{"baz"}, nil}, newEmptyDataFrame(), true},
+		{"fail: unsupported DataType", args{nil, []DummyOptions{{DataType: options.Float64}}}, newEmptyDataFrame(), true},
+		{"fail: multiple configs", args{nil, []DummyOptions{{}, {}}}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := df.GetDummies(tt.args.cols, tt.args.config...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.GetDummies() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.GetDummies() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFrame_FromDummies(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   *DataFrame
+		config  []FromDummiesOptions
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "single level",
+			input: MustNew([]interface{}{[]int{1, 2, 3}, []bool{false, true, false}, []bool{true, false, false}},
+				Config{Col: []string{"bar", "foo_a", "foo_b"}}),
+			want:    MustNew([]interface{}{[]int{1, 2, 3}, []string{"b", "a", ""}}, Config{Col: []string{"bar", "foo"}}),
+			wantErr: false},
+		{"multi level int64",
+			MustNew([]interface{}{[]int{1, 2, 3}, []int64{0, 1, 0}, []int64{1, 0, 0}},
+				Config{MultiCol: [][]string{{"bar", "foo", "foo"}, {"", "a", "b"}}}),
+			nil,
+			MustNew([]interface{}{[]int{1, 2, 3}, []string{"b", "a", ""}}, Config{Col: []string{"bar", "foo"}}),
+			false},
+		{"default category and null category",
+			MustNew([]interface{}{[]bool{true, false, false}, []bool{false, false, true}}, Config{Col: []string{"foo.b", "foo.NaN"}}),
+			[]FromDummiesOptions{{PrefixSep: ".", DefaultCategory: map[string]string{"foo": "a"}}},
+			MustNew([]interface{}{[]string{"b", "a", ""}}, Config{Col: []string{"foo"}}),
+			false},
+		{"bool column with separator is not an indicator",
+			MustNew([]interface{}{[]bool{true, false}, []bool{true, false}, []bool{false, true}},
+				Config{Col: []string{"is_member", "foo_a", "foo_b"}}),
+			nil,
+			MustNew([]interface{}{[]bool{true, false}, []string{"a", "b"}}, Config{Col: []string{"is_member", "foo"}}),
+			false},
+		{"group with several indicators set is not decoded",
+			MustNew([]interface{}{[]bool{true, false}, []bool{true, true}}, Config{Col: []string{"has_email", "has_phone"}}),
+			nil,
+			MustNew([]interface{}{[]bool{true, false}, []bool{true, true}}, Config{Col: []string{"has_email", "has_phone"}}),
+			false},
+		{"single indicator with default category",
+			MustNew([]interface{}{[]int64{1, 0}}, Config{Col: []string{"foo_b"}}),
+			[]FromDummiesOptions{{DefaultCategory: map[string]string{"foo": "a"}}},
+			MustNew([]interface{}{[]string{"b", "a"}}, Config{Col: []string{"foo"}}),
+			false},
+		{"supplied prefixes",
+			MustNew([]interface{}{[]bool{true, false}, []bool{false, true}, []bool{true, true}},
+				Config{Col: []string{"is_member", "foo_a", "foo_b"}}),
+			[]FromDummiesOptions{{Prefixes: []string{"is"}}},
+			MustNew([]interface{}{[]bool{false, true}, []bool{true, true}, []string{"member", ""}}, Config{Col: []string{"foo_a", "foo_b", "is"}}),
+			false},
+		{"fail: multiple indicators set for supplied prefix",
+			MustNew([]interface{}{[]bool{true}, []bool{true}}, Config{Col: []string{"foo_a", "foo_b"}}),
+			[]FromDummiesOptions{{Prefixes: []string{"foo"}}}, newEmptyDataFrame(), true},
+		{"fail: multiple indicators set with two levels",
+			MustNew([]interface{}{[]bool{true}, []bool{true}}, Config{MultiCol: [][]string{{"foo", "foo"}, {"a", "b"}}}),
+			nil, newEmptyDataFrame(), true},
+		{"prefix and non-indicators with separator",
+			MustNew([]interface{}{[]string{"x", "y"}, []bool{true, false}, []bool{false, true}},
+				Config{Col: []string{"customer_id", "unit_price_low", "unit_price_high"}}),
+			nil,
+			MustNew([]interface{}{[]string{"x", "y"}, []string{"low", "high"}}, Config{Col: []string{"customer_id", "unit_price"}}),
+			false},
+		{"fail: multi level string indicator",
+			MustNew([]interface{}{[]string{"true"}}, Config{MultiCol: [][]string{{"foo"}, {"a"}}}),
+			nil, newEmptyDataFrame(), true},
+		{"fail: multiple configs",
+			MustNew([]interface{}{[]bool{true}}, Config{Col: []string{"foo_a"}}),
+			[]FromDummiesOptions{{}, {}}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.FromDummies(tt.config...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.FromDummies() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.FromDummies() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFrame_GetDummies_roundTrip(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3}, []string{"b", "a", "c"}}, Config{Col: []string{"bar", "foo"}})
+	dummies, err := df.GetDummies([]string{"foo"}, DummyOptions{DataType: options.Int64})
+	if err != nil {
+		t.Fatalf("DataFrame.GetDummies() error: %v", err)
+	}
+	got, err := dummies.FromDummies()
+	if err != nil {
+		t.Fatalf("DataFrame.FromDummies() error: %v", err)
+	}
+	if !Equal(got, df) {
+		t.Errorf("DataFrame.FromDummies() got %v, want %v", got, df)
+	}
+}
+
+func TestDataFrame_GetDummies_roundTripSeparatorInLabels(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"c_1", "c_2", "c_3"}, []int{1, 2, 3}, []string{"low", "high", "low"}},
+		Config{Col: []string{"customer_id", "order_qty", "unit_price"}})
+	for _, dataType := range []options.DataType{options.Bool, options.Int64} {
+		dummies, err := df.GetDummies([]string{"unit_price"}, DummyOptions{DataType: dataType})
+		if err != nil {
+			t.Fatalf("DataFrame.GetDummies() error: %v", err)
+		}
+		want := MustNew([]interface{}{[]string{"c_1", "c_2", "c_3"}, []int{1, 2, 3}, []string{"low", "high", "low"}},
+			Config{Col: []string{"customer_id", "order_qty", "unit_price"}})
+		got, err := dummies.FromDummies()
+		if err != nil {
+			t.Fatalf("DataFrame.FromDummies() error: %v", err)
+		}
+		if !Equal(got, want) {
+			t.Errorf("DataFrame.FromDummies() with %v indicators got %v, want %v", dataType, got, want)
+		}
+	}
+}
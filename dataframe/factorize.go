@@ -0,0 +1,221 @@
+package dataframe
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ptiger10/pd/internal/values"
+	"github.com/ptiger10/pd/options"
+)
+
+// reduceByCode applies the math reduction name ("sum", "mean", "min", "max", "median", "std", "var" or "prod") to every numerical column of g,
+// column by column, and returns one row per group in g.Groups() order.
+// Columns whose reduction does not apply to their DataType or is null in every group are excluded, as in DataFrame.Sum() and its siblings.
+// ok is false if the Grouping has no group codes or no column remains.
+func (g Grouping) reduceByCode(name string) (ret *DataFrame, ok bool) {
+	return g.reduceColumnsByCode(name, func(data []float64, valid []bool) []float64 {
+		return reduceFloats(name, data, valid, g.codes, g.Len())
+	})
+}
+
+// quantileByCode returns the quantile q of every numerical column of g, as in DataFrame.Quantile(), with one row per group. See reduceByCode.
+func (g Grouping) quantileByCode(q float64) (ret *DataFrame, ok bool) {
+	return g.reduceColumnsByCode("quantile", func(data []float64, valid []bool) []float64 {
+		return quantileFloats(q, data, valid, g.codes, g.Len())
+	})
+}
+
+// reduceColumnsByCode calls reduce with the values of every column to which the math reduction name applies, and returns one row per group.
+// See reduceByCode.
+func (g Grouping) reduceColumnsByCode(name string, reduce func(data []float64, valid []bool) []float64) (ret *DataFrame, ok bool) {
+	if g.codes == nil || g.Len() == 0 {
+		return nil, false
+	}
+	var vals []values.Container
+	var cols []int
+	for m := 0; m < g.df.NumCols(); m++ {
+		data, valid, applies := floatColumn(g.df.vals[m], name)
+		if !applies {
+			continue
+		}
+		result := reduce(data, valid)
+		allNull := true
+		for _, d := range result {
+			if !math.IsNaN(d) {
+				allNull = false
+				break
+			}
+		}
+		if allNull {
+			continue
+		}
+		vals = append(vals, values.MustCreateValuesFromInterface(result))
+		cols = append(cols, m)
+	}
+	if len(cols) == 0 {
+		return nil, false
+	}
+	retCols := g.df.cols.Copy()
+	retCols.Subset(cols)
+	return newFromComponents(vals, g.df.subsetIndex(g.firstPositions()), retCols, g.df.name), true
+}
+
+// floatColumn returns the values of container as float64 and whether each value is valid,
+// or applies false if the math reduction name does not apply to the container's DataType.
+func floatColumn(container values.Container, name string) (data []float64, valid []bool, applies bool) {
+	n := container.Values.Len()
+	switch container.DataType {
+	case options.Float64:
+		data = container.Values.Vals().([]float64)
+		valid = make([]bool, n)
+		for i, d := range data {
+			valid[i] = !math.IsNaN(d)
+		}
+	case options.Int64:
+		ints := container.Values.Vals().([]int64)
+		data = make([]float64, n)
+		valid = make([]bool, n)
+		for i, d := range ints {
+			data[i] = float64(d)
+			valid[i] = !container.Values.Null(i)
+		}
+	case options.Bool:
+		if name != "sum" && name != "mean" {
+			return nil, nil, false
+		}
+		bools := container.Values.Vals().([]bool)
+		data = make([]float64, n)
+		valid = make([]bool, n)
+		for i, d := range bools {
+			if d {
+				data[i] = 1
+			}
+			valid[i] = !container.Values.Null(i)
+		}
+	default:
+		return nil, nil, false
+	}
+	return data, valid, true
+}
+
+// reduceFloats applies the math reduction name to the valid values of data in each group identified by codes.
+func reduceFloats(name string, data []float64, valid []bool, codes []int, numGroups int) []float64 {
+	ret := make([]float64, numGroups)
+	switch name {
+	case "sum":
+		for i, d := range data {
+			if valid[i] {
+				ret[codes[i]] += d
+			}
+		}
+	case "prod":
+		for k := range ret {
+			ret[k] = 1
+		}
+		for i, d := range data {
+			if valid[i] {
+				ret[codes[i]] *= d
+			}
+		}
+	case "min", "max":
+		for k := range ret {
+			ret[k] = math.NaN()
+		}
+		for i, d := range data {
+			if !valid[i] {
+				continue
+			}
+			code := codes[i]
+			if math.IsNaN(ret[code]) || (name == "min" && d < ret[code]) || (name == "max" && d > ret[code]) {
+				ret[code] = d
+			}
+		}
+	case "median":
+		return quantileFloats(0.5, data, valid, codes, numGroups)
+	case "mean", "var", "std":
+		counts := make([]float64, numGroups)
+		for i, d := range data {
+			if valid[i] {
+				ret[codes[i]] += d
+				counts[codes[i]]++
+			}
+		}
+		for k := range ret {
+			ret[k] /= counts[k]
+		}
+		if name == "mean" {
+			return ret
+		}
+		means := ret
+		ret = make([]float64, numGroups)
+		for i, d := range data {
+			if valid[i] {
+				ret[codes[i]] += (d - means[codes[i]]) * (d - means[codes[i]])
+			}
+		}
+		for k := range ret {
+			ret[k] /= counts[k]
+			if name == "std" {
+				ret[k] = math.Pow(ret[k], 0.5)
+			}
+		}
+	}
+	return ret
+}
+
+// quantileFloats returns the quantile q of the valid values of data in each group identified by codes,
+// interpolated linearly between the closest ranks as in Series.Quantile(), or NaN if q is not between 0 and 1.
+func quantileFloats(q float64, data []float64, valid []bool, codes []int, numGroups int) []float64 {
+	ret := make([]float64, numGroups)
+	if q < 0 || q > 1 {
+		for k := range ret {
+			ret[k] = math.NaN()
+		}
+		return ret
+	}
+	groups := make([][]float64, numGroups)
+	for i, d := range data {
+		if valid[i] {
+			groups[codes[i]] = append(groups[codes[i]], d)
+		}
+	}
+	for k, sorted := range groups {
+		if len(sorted) == 0 {
+			ret[k] = math.NaN()
+			continue
+		}
+		sort.Float64s(sorted)
+		pos := q * float64(len(sorted)-1)
+		lower := int(math.Floor(pos))
+		ret[k] = sorted[lower]
+		if frac := pos - float64(lower); frac > 0 {
+			ret[k] += frac * (sorted[lower+1] - sorted[lower])
+		}
+	}
+	return ret
+}
+
+// countByCode returns the number of non-null values in every column for each group in g.Groups() order.
+func (g Grouping) countByCode() *DataFrame {
+	vals := make([]values.Container, g.df.NumCols())
+	for m := range vals {
+		counts := make([]int64, g.Len())
+		for i, code := range g.codes {
+			if !g.df.vals[m].Values.Null(i) {
+				counts[code]++
+			}
+		}
+		vals[m] = values.MustCreateValuesFromInterface(counts)
+	}
+	return newFromComponents(vals, g.df.subsetIndex(g.firstPositions()), g.df.cols.Copy(), g.df.name)
+}
+
+// firstPositions returns the first position of each group in g.Groups() order.
+func (g Grouping) firstPositions() []int {
+	ids := g.ids()
+	ret := make([]int, len(ids))
+	for k, id := range ids {
+		ret[k] = g.groups[id].FirstPosition
+	}
+	return ret
+}
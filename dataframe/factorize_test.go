@@ -0,0 +1,72 @@
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/ptiger10/pd/series"
+)
+
+func TestGrouping_reduceByCode(t *testing.T) {
+	df := MustNew([]interface{}{
+		[]float64{1, math.NaN(), 3, 4, 5, 6},
+		[]int{1, 2, 3, 4, 5, 6},
+		[]bool{true, false, true, true, false, false},
+		[]string{"foo", "bar", "baz", "qux", "quux", "corge"},
+	}, Config{Col: []string{"A", "B", "C", "D"}, MultiIndex: []interface{}{[]int{1, 2, 1, 2, 1, 3}, []string{"a", "a", "a", "a", "b", "b"}}})
+	g := df.GroupByIndex()
+	tests := []struct {
+		name string
+		fn   func(*DataFrame) *series.Series
+		got  func() (*DataFrame, bool)
+	}{
+		{"sum", (*DataFrame).Sum, func() (*DataFrame, bool) { return g.reduceByCode("sum") }},
+		{"mean", (*DataFrame).Mean, func() (*DataFrame, bool) { return g.reduceByCode("mean") }},
+		{"min", (*DataFrame).Min, func() (*DataFrame, bool) { return g.reduceByCode("min") }},
+		{"max", (*DataFrame).Max, func() (*DataFrame, bool) { return g.reduceByCode("max") }},
+		{"median", (*DataFrame).Median, func() (*DataFrame, bool) { return g.reduceByCode("median") }},
+		{"std", (*DataFrame).Std, func() (*DataFrame, bool) { return g.reduceByCode("std") }},
+		{"var", (*DataFrame).Var, func() (*DataFrame, bool) { return g.reduceByCode("var") }},
+		{"prod", (*DataFrame).Prod, func() (*DataFrame, bool) { return g.reduceByCode("prod") }},
+		{"quantile", func(df *DataFrame) *series.Series { return df.Quantile(0.25) },
+			func() (*DataFrame, bool) { return g.quantileByCode(0.25) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.got()
+			if !ok {
+				t.Fatalf("Grouping.reduceByCode(%v) not ok", tt.name)
+			}
+			// every group must match the ungrouped reduction of its rows, column by column
+			for k, key := range g.Keys() {
+				want := tt.fn(g.GroupByKey(key.Labels...))
+				for m := 0; m < got.NumCols(); m++ {
+					gotVal := got.vals[m].Values.Value(k)
+					wantVal := math.NaN()
+					for i := 0; i < want.Len(); i++ {
+						if want.Element(i).Labels[0] == got.cols.Name(m) {
+							wantVal = want.At(i).(float64)
+						}
+					}
+					if fmt.Sprint(gotVal) != fmt.Sprint(wantVal) {
+						t.Errorf("Grouping.reduceByCode(%v) group %v col %v = %v, want %v", tt.name, key, got.cols.Name(m), gotVal, wantVal)
+					}
+				}
+			}
+		})
+	}
+	if _, ok := newEmptyGrouping().reduceByCode("sum"); ok {
+		t.Errorf("Grouping.reduceByCode() on empty Grouping ok, want not ok")
+	}
+	strs := MustNew([]interface{}{[]string{"foo", "bar"}}, Config{Index: []int{1, 2}})
+	if _, ok := strs.GroupByIndex().reduceByCode("sum"); ok {
+		t.Errorf("Grouping.reduceByCode() without numerical columns ok, want not ok")
+	}
+
+	gotCount := g.Count()
+	wantCount := g.Reduce(series.AggCount)
+	if !Equal(gotCount, wantCount) {
+		t.Errorf("Grouping.Count() = %v, want %v", gotCount, wantCount)
+	}
+}
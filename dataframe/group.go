@@ -3,10 +3,13 @@ package dataframe
 import (
 	"fmt"
 	"log"
+	"math"
+	"reflect"
 	"sort"
 	"strings"
-	"sync"
+	"time"
 
+	"github.com/ptiger10/pd/internal/index"
 	"github.com/ptiger10/pd/internal/values"
 	"github.com/ptiger10/pd/options"
 	"github.com/ptiger10/pd/series"
@@ -15,6 +18,7 @@ import (
 type group struct {
 	Positions     []int
 	FirstPosition int
+	Key           GroupKey
 }
 
 func (grp *group) copy() *group {
@@ -22,7 +26,9 @@ func (grp *group) copy() *group {
 	for i, p := range grp.Positions {
 		pos[i] = p
 	}
-	return &group{Positions: pos, FirstPosition: grp.FirstPosition}
+	labels := make([]interface{}, len(grp.Key.Labels))
+	copy(labels, grp.Key.Labels)
+	return &group{Positions: pos, FirstPosition: grp.FirstPosition, Key: GroupKey{Labels: labels}}
 }
 
 // copy a grouping
@@ -31,61 +37,91 @@ func (g Grouping) copy() Grouping {
 	for k, v := range g.groups {
 		grps[k] = v.copy()
 	}
+	codes := make([]int, len(g.codes))
+	copy(codes, g.codes)
+	order := make([]string, len(g.order))
+	copy(order, g.order)
 	return Grouping{
 		df:     g.df.Copy(),
 		groups: grps,
+		source: g.source,
+		codes:  codes,
+		order:  order,
 	}
 }
 
 // SortedGroups returns all valid group labels in the Grouping, sorted in alphabetical order.
 func (g Grouping) SortedGroups() []string {
-	var keys []string
-	for k := range g.groups {
-		keys = append(keys, k)
-	}
+	keys := g.Groups()
 	sort.Strings(keys)
 	return keys
 }
 
 // Groups returns all valid group labels in the Grouping, in their original group position.
+// Each label is the String() of the group's GroupKey.
 func (g Grouping) Groups() []string {
-	type groupContainer struct {
-		grp   *group
-		label string
-	}
-	var orderedGroups []groupContainer
-	for k, v := range g.groups {
-		orderedGroups = append(orderedGroups, groupContainer{grp: v, label: k})
-	}
-	sort.Slice(orderedGroups, func(i, j int) bool {
-		if orderedGroups[i].grp.FirstPosition < orderedGroups[j].grp.FirstPosition {
-			return true
-		}
-		return false
-	})
 	var labels []string
-	for _, grp := range orderedGroups {
-		labels = append(labels, grp.label)
+	for _, id := range g.ids() {
+		labels = append(labels, g.groups[id].Key.String())
 	}
 	return labels
 }
 
+// Keys returns the GroupKey of every group in the Grouping, in their original group position.
+func (g Grouping) Keys() []GroupKey {
+	var keys []GroupKey
+	for _, id := range g.ids() {
+		keys = append(keys, g.groups[id].copy().Key)
+	}
+	return keys
+}
+
+// ids returns the hashes that identify the groups in g.groups, in their original group position.
+func (g Grouping) ids() []string {
+	return g.order
+}
+
 // Len returns the number of groups in the Grouping.
 func (g Grouping) Len() int {
 	return len(g.groups)
 }
 
 // Group returns the DataFrame with the given group label, or an error if that label does not exist.
+// If several groups share the label (e.g., the labels 1 and "1"), the first group is returned. Use GroupByKey to select among them.
 func (g Grouping) Group(label string) *DataFrame {
-	group, ok := g.groups[label]
+	for _, id := range g.ids() {
+		if g.groups[id].Key.String() == label {
+			return g.df.subsetRows(g.groups[id].Positions)
+		}
+	}
+	if options.GetLogWarnings() {
+		log.Printf("s.Grouping.Group(): label %v not in g.Groups()", label)
+	}
+	return newEmptyDataFrame()
+}
+
+// GroupByKey returns the DataFrame of the group with the given label in each grouped index level, in level order.
+// Labels match only labels of the same type. In typed index levels, Go scalars are first converted to their DataType (e.g., int to Int64).
+// If no group has those labels, an empty DataFrame is returned.
+func (g Grouping) GroupByKey(labels ...interface{}) *DataFrame {
+	key := make([]interface{}, len(labels))
+	for j, label := range labels {
+		key[j] = label
+		if j >= g.df.IndexLevels() || g.df.index.Levels[j].DataType == options.Interface {
+			continue
+		}
+		if container, err := values.ScalarFactory(label); err == nil {
+			key[j] = container.Values.Value(0)
+		}
+	}
+	group, ok := g.groups[values.MultiKey(key)]
 	if !ok {
 		if options.GetLogWarnings() {
-			log.Printf("s.Grouping.Group(): label %v not in g.Groups()", label)
+			log.Printf("s.Grouping.GroupByKey(): key %v not in g.Keys()", labels)
 		}
 		return newEmptyDataFrame()
 	}
-	s := g.df.subsetRows(group.Positions)
-	return s
+	return g.df.subsetRows(group.Positions)
 }
 
 func newEmptyGrouping() Grouping {
@@ -96,6 +132,7 @@ func newEmptyGrouping() Grouping {
 
 // GroupByIndex groups a DataFrame by one or more of its index levels. If no level is provided, all index levels are used.
 func (df *DataFrame) GroupByIndex(levelPositions ...int) Grouping {
+	source := df
 	if len(levelPositions) != 0 {
 		df = df.Copy()
 		err := df.Index.SubsetLevels(levelPositions)
@@ -108,7 +145,9 @@ func (df *DataFrame) GroupByIndex(levelPositions ...int) Grouping {
 	}
 
 	// Default: use all label level positions
-	return df.groupby()
+	g := df.groupby()
+	g.source = source
+	return g
 }
 
 // GroupBy groups a DataFrame by one or more columns.
@@ -132,10 +171,98 @@ func (df *DataFrame) GroupBy(cols ...int) Grouping {
 		}
 		return newEmptyGrouping()
 	}
+	source := df
 	df = df.Copy()
 	df.InPlace.replaceIndex(cols)
 
-	return df.groupby()
+	g := df.groupby()
+	g.source = source
+	return g
+}
+
+// GroupByCols groups a DataFrame by one or more columns, identified by their labels in column level 0.
+// The key columns are moved into the index, as in GroupBy, except that every column may be a key,
+// in which case the grouped DataFrame has no value columns (e.g., for Size, Keys or NGroup).
+// To keep the key columns, use GroupByColsWithOptions.
+// If no column is supplied or an invalid column is supplied, an empty grouping is returned.
+func (df *DataFrame) GroupByCols(names ...string) Grouping {
+	return df.GroupByColsWithOptions(names, GroupByOptions{})
+}
+
+// GroupByColsWithOptions groups a DataFrame by one or more columns, like GroupByCols, customized by config.
+func (df *DataFrame) GroupByColsWithOptions(names []string, config GroupByOptions) Grouping {
+	if len(names) == 0 {
+		if options.GetLogWarnings() {
+			log.Print("df.GroupByColsWithOptions(): empty names, returning empty Grouping\n")
+		}
+		return newEmptyGrouping()
+	}
+	cols, err := df.colPositions(names)
+	if err != nil {
+		if options.GetLogWarnings() {
+			log.Printf("df.GroupByColsWithOptions(): %v\n", err)
+		}
+		return newEmptyGrouping()
+	}
+	if !config.KeepKeys && len(cols) < df.NumCols() {
+		return df.GroupBy(cols...)
+	}
+	levels := make([]index.Level, len(cols))
+	for j, col := range cols {
+		levels[j] = index.Level{
+			Name: df.cols.Name(col), Labels: df.vals[col].Values.Copy(), DataType: df.vals[col].DataType, NeedsRefresh: true,
+		}
+	}
+	if !config.KeepKeys {
+		return df.keysOnly().groupByLevels(levels)
+	}
+	return df.groupByLevels(levels)
+}
+
+// keysOnly returns a DataFrame with the index and column levels of df but no columns, for grouping by every column.
+func (df *DataFrame) keysOnly() *DataFrame {
+	cols := df.cols.Copy()
+	cols.Subset(nil)
+	return newFromComponents([]values.Container{}, df.index, cols, df.name)
+}
+
+// GroupBySeries groups a DataFrame by the values of keys, which is aligned to the DataFrame by position and must have the same length.
+// The key values replace the index, in a level named after keys. If keys is invalid, an empty grouping is returned.
+func (df *DataFrame) GroupBySeries(keys *series.Series) Grouping {
+	if keys == nil || keys.Len() != df.Len() {
+		if options.GetLogWarnings() {
+			log.Print("df.GroupBySeries(): keys must have the same length as the DataFrame\n")
+		}
+		return newEmptyGrouping()
+	}
+	container, _ := keys.ToInternalComponents()
+	level := index.Level{Name: keys.Name(), Labels: container.Values, DataType: container.DataType, NeedsRefresh: true}
+	return df.groupByLevels([]index.Level{level})
+}
+
+// GroupByFunc groups a DataFrame by the key that fn returns for each row.
+// The keys replace the index, in an unnamed level with the common DataType of the keys. A nil key is null.
+func (df *DataFrame) GroupByFunc(fn func(Row) interface{}) Grouping {
+	if df.Len() == 0 {
+		return newEmptyGrouping()
+	}
+	keys := make([]interface{}, df.Len())
+	for i := range keys {
+		keys[i] = fn(df.Row(i))
+	}
+	container := values.ScalarSliceFactory(keys)
+	level := index.Level{Labels: container.Values, DataType: container.DataType, NeedsRefresh: true}
+	return df.groupByLevels([]index.Level{level})
+}
+
+// groupByLevels groups df with its index replaced by levels. Like GroupByIndex, the Grouping shares the values of df.
+func (df *DataFrame) groupByLevels(levels []index.Level) Grouping {
+	idx := index.New(levels...)
+	idx.UpdateNameMap()
+	grouped := newFromComponents(df.vals, idx, df.cols.Copy(), df.name)
+	g := grouped.groupby()
+	g.source = df
+	return g
 }
 
 func (ip InPlace) replaceIndex(cols []int) {
@@ -153,140 +280,681 @@ func (ip InPlace) replaceIndex(cols []int) {
 
 func (df *DataFrame) groupby() Grouping {
 	groups := make(map[string]*group)
-	for i := 0; i < df.Len(); i++ {
-		labels := df.Row(i).Labels
-		var strLabels []string
-		for _, label := range labels {
-			strLabels = append(strLabels, fmt.Sprint(label))
-		}
-		groupLabel := strings.Join(strLabels, values.GetMultiColNameSeparator())
-
-		// create group with groupLabel and index labels if it is not already within groups map
-		if _, ok := groups[groupLabel]; !ok {
-			groups[groupLabel] = &group{FirstPosition: i}
-		}
-		groups[groupLabel].Positions = append(groups[groupLabel].Positions, i)
+	// the index rather than df.Len() counts the rows, because a DataFrame grouped by every column has no columns
+	n := df.index.Len()
+	if n == 0 {
+		return Grouping{df: df, groups: groups}
 	}
-	return Grouping{df: df, groups: groups}
+	codes, numCodes := df.index.Factorize()
+	order := make([]string, numCodes)
+	for k, positions := range values.GroupPositions(codes, numCodes) {
+		grp := &group{Positions: positions, FirstPosition: positions[0], Key: GroupKey{Labels: df.index.Elements(positions[0]).Labels}}
+		order[k] = values.MultiKey(grp.Key.Labels)
+		groups[order[k]] = grp
+	}
+	return Grouping{df: df, groups: groups, codes: codes, order: order}
 }
 
 // First returns the first occurrence of each grouping in the DataFrame.
 func (g Grouping) First() *DataFrame {
-	first := func(group string) *DataFrame {
-		position := g.groups[group].Positions[0]
-		df := g.df.subsetRows([]int{position})
-		return df
-	}
-	ret := newEmptyDataFrame()
-	for _, group := range g.Groups() {
-		df := first(group)
-		ret.InPlace.appendDataFrameRow(df)
+	if g.Len() == 0 {
+		return newEmptyDataFrame()
 	}
-	return ret
+	return g.df.subsetFrame(g.firstPositions())
 }
 
 // Last returns the last occurrence of each grouping in the DataFrame.
 func (g Grouping) Last() *DataFrame {
-	last := func(group string) *DataFrame {
-		lastIdx := len(g.groups[group].Positions) - 1
-		position := g.groups[group].Positions[lastIdx]
-		df := g.df.subsetRows([]int{position})
-		return df
+	if g.Len() == 0 {
+		return newEmptyDataFrame()
 	}
-	ret := newEmptyDataFrame()
-	for _, group := range g.Groups() {
-		df := last(group)
-		ret.InPlace.appendDataFrameRow(df)
+	ids := g.ids()
+	positions := make([]int, len(ids))
+	for k, id := range ids {
+		positions[k] = g.groups[id].Positions[len(g.groups[id].Positions)-1]
 	}
-	return ret
+	return g.df.subsetFrame(positions)
 }
 
-type calcReturn struct {
-	df *DataFrame
-	n  int
-}
-
-func (g Grouping) asyncMath(fn func(*DataFrame) *series.Series) *DataFrame {
-	var wg sync.WaitGroup
-	// synchronous option
-	if !options.GetAsync() {
-		ret := newEmptyDataFrame()
-		for _, group := range g.Groups() {
-			calc := g.mathSingleGroup(group, fn)
-			ret.InPlace.appendDataFrameRow(calc)
-		}
+// math reduces every numerical column of each group by group code (see reduceByCode),
+// or returns an empty DataFrame if no column can be reduced.
+func (g Grouping) math(name string) *DataFrame {
+	if ret, ok := g.reduceByCode(name); ok {
 		return ret
 	}
-
-	// asynchronous option
-	ch := make(chan calcReturn, g.Len())
-	for i, group := range g.Groups() {
-		wg.Add(1)
-		go g.awaitMath(ch, i, group, fn, &wg)
-	}
-	wg.Wait()
-	close(ch)
-	var returnedData []calcReturn
-	for result := range ch {
-		returnedData = append(returnedData, result)
-	}
-	sort.Slice(returnedData, func(i, j int) bool {
-		return returnedData[i].n < returnedData[j].n
-	})
-
-	df := newEmptyDataFrame()
-	for _, result := range returnedData {
-		df.InPlace.appendDataFrameRow((result.df))
-	}
-	df.index.NeedsRefresh = true
-	return df
-}
-
-func (g Grouping) awaitMath(
-	ch chan<- calcReturn, n int, group string,
-	fn func(*DataFrame) *series.Series, wg *sync.WaitGroup,
-) {
-	df := g.mathSingleGroup(group, fn)
-	ret := calcReturn{df: df, n: n}
-	ch <- ret
-	wg.Done()
-}
-
-func (g Grouping) mathSingleGroup(group string, fn func(*DataFrame) *series.Series) *DataFrame {
-	positions := g.groups[group].Positions
-	rows := g.df.subsetRows(positions)
-	calc := fn(rows)
-	calc.Rename(group)
-	df := transposeSeries(calc)
-	return df
+	return newEmptyDataFrame()
 }
 
 // Sum for each group in the Grouping.
 func (g Grouping) Sum() *DataFrame {
-	return g.asyncMath((*DataFrame).Sum)
+	return g.math("sum")
 }
 
 // Mean for each group in the Grouping.
 func (g Grouping) Mean() *DataFrame {
-	return g.asyncMath((*DataFrame).Mean)
+	return g.math("mean")
 }
 
 // Min for each group in the Grouping.
 func (g Grouping) Min() *DataFrame {
-	return g.asyncMath((*DataFrame).Min)
+	return g.math("min")
 }
 
 // Max for each group in the Grouping.
 func (g Grouping) Max() *DataFrame {
-	return g.asyncMath((*DataFrame).Max)
+	return g.math("max")
 }
 
 // Median for each group in the Grouping.
 func (g Grouping) Median() *DataFrame {
-	return g.asyncMath((*DataFrame).Median)
+	return g.math("median")
 }
 
 // Std for each group in the Grouping.
 func (g Grouping) Std() *DataFrame {
-	return g.asyncMath((*DataFrame).Std)
+	return g.math("std")
+}
+
+// Var for each group in the Grouping.
+func (g Grouping) Var() *DataFrame {
+	return g.math("var")
+}
+
+// Prod for each group in the Grouping.
+func (g Grouping) Prod() *DataFrame {
+	return g.math("prod")
+}
+
+// Quantile q for each group in the Grouping.
+func (g Grouping) Quantile(q float64) *DataFrame {
+	if ret, ok := g.quantileByCode(q); ok {
+		return ret
+	}
+	return newEmptyDataFrame()
+}
+
+// Count of non-null values in every column for each group in the Grouping.
+func (g Grouping) Count() *DataFrame {
+	if g.codes != nil {
+		return g.countByCode()
+	}
+	return g.Reduce(series.AggCount)
+}
+
+// Size returns the number of rows in each group in the Grouping, in g.Groups() order.
+func (g Grouping) Size() *series.Series {
+	if g.Len() == 0 {
+		return series.MustNew(nil)
+	}
+	groups := g.ids()
+	sizes := make([]int64, len(groups))
+	firstPositions := make([]int, len(groups))
+	for k, group := range groups {
+		sizes[k] = int64(len(g.groups[group].Positions))
+		firstPositions[k] = g.groups[group].FirstPosition
+	}
+	return series.FromInternalComponents(values.MustCreateValuesFromInterface(sizes), g.df.subsetIndex(firstPositions), "size")
+}
+
+// NUnique returns the number of unique non-null values in every column for each group in the Grouping.
+func (g Grouping) NUnique() *DataFrame {
+	return g.Reduce(series.AggNUnique)
+}
+
+// Mode returns the most common non-null value in every column for each group in the Grouping.
+func (g Grouping) Mode() *DataFrame {
+	return g.Reduce(series.AggMode)
+}
+
+// FirstValid returns the first non-null value in every column for each group in the Grouping.
+func (g Grouping) FirstValid() *DataFrame {
+	return g.Reduce(series.AggFirst)
+}
+
+// LastValid returns the last non-null value in every column for each group in the Grouping.
+func (g Grouping) LastValid() *DataFrame {
+	return g.Reduce(series.AggLast)
+}
+
+// Nth returns the row at position n of each group in the Grouping, counting from the end if n is negative.
+// The row is null if n is out of range for the group.
+func (g Grouping) Nth(n int) *DataFrame {
+	return g.Reduce(series.AggNth(n))
+}
+
+// Reduce applies agg to every column of each group in the Grouping and returns a new DataFrame with one row per group, in g.Groups() order.
+// Each column has the common DataType of its reduced values. Groups are reduced concurrently if options.GetAsync() is true.
+func (g Grouping) Reduce(agg series.Agg) *DataFrame {
+	if g.Len() == 0 {
+		return newEmptyDataFrame()
+	}
+	groups := g.ids()
+	results := make([][]interface{}, g.df.NumCols())
+	for m := range results {
+		results[m] = make([]interface{}, len(groups))
+	}
+	firstPositions := make([]int, len(groups))
+	values.ForEachGroup(len(groups), func(k int) {
+		grp := g.groups[groups[k]]
+		firstPositions[k] = grp.FirstPosition
+		for m := range results {
+			results[m][k] = agg.Eval(g.df.subsetSeries(m, grp.Positions))
+		}
+	})
+	vals := make([]values.Container, len(results))
+	for m := range results {
+		vals[m] = values.ScalarSliceFactory(results[m])
+	}
+	return newFromComponents(vals, g.df.subsetIndex(firstPositions), g.df.cols.Copy(), g.df.name)
+}
+
+// Agg applies the aggregations in specs to the columns they are keyed by (labels in column level 0)
+// and returns a new DataFrame with one row per group, in g.Groups() order, and one column per column and aggregation.
+// Columns are in their original order, and aggregations in the order supplied. Each column has the common DataType of its reduced values,
+// so non-numeric results (e.g., the AggMax of a DateTime column) keep their type.
+// Groups are reduced concurrently if options.GetAsync() is true. See AggOptions for labeling the result columns.
+func (g Grouping) Agg(specs map[string][]series.Agg, config ...AggOptions) (*DataFrame, error) {
+	tmp := AggOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return newEmptyDataFrame(), fmt.Errorf("Grouping.Agg(): can supply at most one AggOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	if len(specs) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("Grouping.Agg(): must supply at least one aggregation")
+	}
+	if g.Len() == 0 {
+		return newEmptyDataFrame(), nil
+	}
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	positions, err := g.df.colPositions(names)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("Grouping.Agg(): %v", err)
+	}
+	colNames := make(map[int]string, len(names))
+	for k, m := range positions {
+		colNames[m] = names[k]
+	}
+	sort.Ints(positions)
+
+	var cols []int
+	var aggs []series.Agg
+	var colLabels, aggLabels []string
+	for _, m := range positions {
+		name := colNames[m]
+		seen := make(map[string]bool)
+		for _, agg := range specs[name] {
+			if agg.Name() == "" {
+				return newEmptyDataFrame(), fmt.Errorf("Grouping.Agg(): aggregations for column %v must have names", name)
+			}
+			if seen[agg.Name()] {
+				return newEmptyDataFrame(), fmt.Errorf("Grouping.Agg(): duplicate aggregation %v for column %v", agg.Name(), name)
+			}
+			seen[agg.Name()] = true
+			cols = append(cols, m)
+			aggs = append(aggs, agg)
+			colLabels = append(colLabels, name)
+			aggLabels = append(aggLabels, agg.Name())
+		}
+	}
+	if len(aggs) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("Grouping.Agg(): must supply at least one aggregation")
+	}
+
+	groups := g.ids()
+	results := make([][]interface{}, len(aggs))
+	for k := range results {
+		results[k] = make([]interface{}, len(groups))
+	}
+	firstPositions := make([]int, len(groups))
+	values.ForEachGroup(len(groups), func(i int) {
+		grp := g.groups[groups[i]]
+		firstPositions[i] = grp.FirstPosition
+		subsets := make(map[int]*series.Series)
+		for k, m := range cols {
+			if _, ok := subsets[m]; !ok {
+				subsets[m] = g.df.subsetSeries(m, grp.Positions)
+			}
+			results[k][i] = aggs[k].Eval(subsets[m])
+		}
+	})
+	vals := make([]values.Container, len(results))
+	for k := range results {
+		vals[k] = values.ScalarSliceFactory(results[k])
+	}
+
+	var newCols index.Columns
+	if tmp.MultiLevel {
+		newCols = index.NewColumns(index.NewColLevel(colLabels, g.df.cols.Levels[0].Name), index.NewColLevel(aggLabels, ""))
+	} else {
+		labels := make([]string, len(colLabels))
+		for k := range labels {
+			labels[k] = colLabels[k] + "_" + aggLabels[k]
+		}
+		newCols = index.NewColumns(index.NewColLevel(labels, g.df.cols.Levels[0].Name))
+	}
+	return newFromComponents(vals, g.df.subsetIndex(firstPositions), newCols, g.df.name), nil
+}
+
+// Each calls fn with the key and rows of each group in the Grouping, one group at a time, and stops at the first error.
+// Only the rows of the current group are copied from the grouped DataFrame. See EachOptions for the order of the groups.
+func (g Grouping) Each(fn func(key GroupKey, df *DataFrame) error, config ...EachOptions) error {
+	tmp := EachOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return fmt.Errorf("Grouping.Each(): can supply at most one EachOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	ids, err := g.sortedIDs(tmp.Sort)
+	if err != nil {
+		return fmt.Errorf("Grouping.Each(): %v", err)
+	}
+	for _, id := range ids {
+		grp := g.groups[id]
+		if err := fn(grp.copy().Key, g.df.subsetFrame(grp.Positions)); err != nil {
+			return fmt.Errorf("Grouping.Each(): group %v: %v", grp.Key, err)
+		}
+	}
+	return nil
+}
+
+// sortedIDs returns the ids of the groups in the order named by sort (see EachOptions).
+func (g Grouping) sortedIDs(order string) ([]string, error) {
+	ids := make([]string, len(g.order))
+	copy(ids, g.order)
+	switch order {
+	case "", "first":
+	case "sorted":
+		sort.SliceStable(ids, func(i, j int) bool {
+			return g.groups[ids[i]].Key.String() < g.groups[ids[j]].Key.String()
+		})
+	case "natural":
+		sort.SliceStable(ids, func(i, j int) bool {
+			return compareKeys(g.groups[ids[i]].Key, g.groups[ids[j]].Key) < 0
+		})
+	default:
+		return nil, fmt.Errorf("Sort must be first, sorted, natural, or empty, not %q", order)
+	}
+	return ids, nil
+}
+
+// compareKeys compares two keys level by level in natural order (see EachOptions), and returns -1, 0 or 1.
+func compareKeys(a, b GroupKey) int {
+	for j := 0; j < len(a.Labels) && j < len(b.Labels); j++ {
+		if c := compareLabels(a.Labels[j], b.Labels[j]); c != 0 {
+			return c
+		}
+	}
+	return len(a.Labels) - len(b.Labels)
+}
+
+// compareLabels compares two labels in natural order (see EachOptions), and returns -1, 0 or 1.
+func compareLabels(a, b interface{}) int {
+	aNull, bNull := isNullLabel(a), isNullLabel(b)
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull:
+		return 1
+	case bNull:
+		return -1
+	}
+	// compare int64 directly to avoid losing precision in large values
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return compareOrdered(x < y, x > y)
+		}
+	}
+	if x, ok := numericLabel(a); ok {
+		if y, ok := numericLabel(b); ok {
+			return compareOrdered(x < y, x > y)
+		}
+	}
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return compareOrdered(x.Before(y), x.After(y))
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareOrdered(!x && y, x && !y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// isNullLabel returns true if label is nil or a NaN float.
+func isNullLabel(label interface{}) bool {
+	switch v := label.(type) {
+	case nil:
+		return true
+	case float64:
+		return math.IsNaN(v)
+	case float32:
+		return math.IsNaN(float64(v))
+	}
+	return false
+}
+
+// numericLabel returns label as a float64 if it is a number.
+func numericLabel(label interface{}) (float64, bool) {
+	switch v := label.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// Apply calls fn with the key and rows of each group in the Grouping, and concatenates the returned DataFrames in g.Groups() order,
+// with the group labels as the outer index levels. Groups are processed concurrently if options.GetAsync() is true.
+// Results that are nil or empty are skipped. The results must have the same columns and number of index levels,
+// and each column has the common DataType of the concatenated columns.
+// If fn returns an error for any group, Apply returns all of the errors together.
+func (g Grouping) Apply(fn func(key GroupKey, df *DataFrame) (*DataFrame, error)) (*DataFrame, error) {
+	if g.Len() == 0 {
+		return newEmptyDataFrame(), nil
+	}
+	groups := g.ids()
+	results := make([]*DataFrame, len(groups))
+	errs := make([]error, len(groups))
+	values.ForEachGroup(len(groups), func(k int) {
+		grp := g.groups[groups[k]]
+		results[k], errs[k] = fn(grp.copy().Key, g.df.subsetFrame(grp.Positions))
+	})
+	var msgs []string
+	for k, err := range errs {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("group %v: %v", g.groups[groups[k]].Key, err))
+		}
+	}
+	if len(msgs) > 0 {
+		return newEmptyDataFrame(), fmt.Errorf("Grouping.Apply(): %d errors: %s", len(msgs), strings.Join(msgs, "; "))
+	}
+
+	var dfs []*DataFrame
+	var keyPositions []int
+	for k, df := range results {
+		if df == nil || df.Len() == 0 {
+			continue
+		}
+		dfs = append(dfs, df)
+		for i := 0; i < df.Len(); i++ {
+			keyPositions = append(keyPositions, g.groups[groups[k]].FirstPosition)
+		}
+	}
+	if len(dfs) == 0 {
+		return newEmptyDataFrame(), nil
+	}
+	ret, err := concatRows(dfs)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("Grouping.Apply(): %v", err)
+	}
+	levels := append(g.df.subsetIndex(keyPositions).Levels, ret.index.Levels...)
+	ret.index = index.New(levels...)
+	return ret, nil
+}
+
+// concatRows returns a new DataFrame with the rows of every DataFrame in dfs, in order.
+// The DataFrames must have the same columns and number of index levels.
+// Each column and index level has the common DataType of the concatenated columns or levels.
+func concatRows(dfs []*DataFrame) (*DataFrame, error) {
+	first := dfs[0]
+	for k, df := range dfs[1:] {
+		if df.IndexLevels() != first.IndexLevels() {
+			return nil, fmt.Errorf("DataFrame %d has %d index levels, want %d", k+1, df.IndexLevels(), first.IndexLevels())
+		}
+		if !reflect.DeepEqual(df.cols.Names(), first.cols.Names()) {
+			return nil, fmt.Errorf("DataFrame %d has columns %v, want %v", k+1, df.cols.Names(), first.cols.Names())
+		}
+	}
+	concat := func(get func(df *DataFrame) values.Container) values.Container {
+		dataTypes := make([]options.DataType, len(dfs))
+		for k, df := range dfs {
+			dataTypes[k] = get(df).DataType
+		}
+		dataType := values.CommonDataType(dataTypes...)
+		var ret values.Values
+		for _, df := range dfs {
+			// ducks error because dataType is the DataType of an existing column or level
+			converted, _ := values.Convert(get(df).Values, dataType)
+			if ret == nil {
+				ret = converted
+				continue
+			}
+			ret.Append(converted)
+		}
+		return values.Container{Values: ret, DataType: dataType}
+	}
+	vals := make([]values.Container, first.NumCols())
+	for m := range vals {
+		vals[m] = concat(func(df *DataFrame) values.Container { return df.vals[m] })
+	}
+	levels := make([]index.Level, first.IndexLevels())
+	for j := range levels {
+		labels := concat(func(df *DataFrame) values.Container {
+			return values.Container{Values: df.index.Levels[j].Labels, DataType: df.index.Levels[j].DataType}
+		})
+		levels[j] = index.Level{Labels: labels.Values, DataType: labels.DataType, Name: first.index.Levels[j].Name, NeedsRefresh: true}
+	}
+	return newFromComponents(vals, index.New(levels...), first.cols.Copy(), first.name), nil
+}
+
+// Transform calls fn with the values in column col (a label in column level 0) of each group in the Grouping, and returns a new Series
+// with the results in the original index and row order of the grouped DataFrame. fn may return a single value, which fills every row in the group
+// (e.g., a group total), or one value per row in the group (e.g., a within-group z-score), in group order.
+// The result has the common DataType of the returned values. Groups are transformed concurrently if options.GetAsync() is true.
+// Returns an error if fn returns nil or any other number of values for a group.
+func (g Grouping) Transform(col string, fn func(*series.Series) *series.Series) (*series.Series, error) {
+	positions, err := g.df.colPositions([]string{col})
+	if err != nil {
+		return series.MustNew(nil), fmt.Errorf("Grouping.Transform(): %v", err)
+	}
+	m := positions[0]
+	if g.Len() == 0 {
+		return series.MustNew(nil), nil
+	}
+	groups := g.ids()
+	results := make([]values.Container, len(groups))
+	valid := make([]bool, len(groups))
+	values.ForEachGroup(len(groups), func(k int) {
+		if result := fn(g.df.subsetSeries(m, g.groups[groups[k]].Positions)); result != nil {
+			results[k], _ = result.ToInternalComponents()
+			valid[k] = true
+		}
+	})
+	dataTypes := make([]options.DataType, len(groups))
+	for k, result := range results {
+		if !valid[k] {
+			return series.MustNew(nil), fmt.Errorf("Grouping.Transform(): group %v: fn returned nil", g.groups[groups[k]].Key)
+		}
+		if n := len(g.groups[groups[k]].Positions); result.Values.Len() != 1 && result.Values.Len() != n {
+			return series.MustNew(nil), fmt.Errorf("Grouping.Transform(): group %v: fn returned %d values, want 1 or %d",
+				g.groups[groups[k]].Key, result.Values.Len(), n)
+		}
+		dataTypes[k] = result.DataType
+	}
+	dataType := values.CommonDataType(dataTypes...)
+	container := values.MakeNullContainer(g.df.index.Len(), dataType)
+	for k, result := range results {
+		// ducks error because dataType is the DataType of an existing Series
+		converted, _ := values.Convert(result.Values, dataType)
+		for i, pos := range g.groups[groups[k]].Positions {
+			src := i
+			if result.Values.Len() == 1 {
+				src = 0
+			}
+			if !converted.Null(src) {
+				container.Values.Set(pos, converted.Value(src))
+			}
+		}
+	}
+	return series.FromInternalComponents(container, g.source.index.Copy(), g.df.cols.Name(m)), nil
+}
+
+// TransformAgg applies agg to column col (a label in column level 0) of each group in the Grouping, and returns a new Series
+// in which every row has the result for its group, in the original index and row order of the grouped DataFrame. See Transform.
+func (g Grouping) TransformAgg(col string, agg series.Agg) (*series.Series, error) {
+	return g.Transform(col, func(s *series.Series) *series.Series {
+		return series.FromInternalComponents(values.ScalarSliceFactory([]interface{}{agg.Eval(s)}), index.NewDefault(1), s.Name())
+	})
+}
+
+// Filter calls fn with the rows of each group in the Grouping and returns a new DataFrame with the original rows
+// of every group for which fn returns true, in their original order. Groups are evaluated concurrently if options.GetAsync() is true.
+func (g Grouping) Filter(fn func(*DataFrame) bool) *DataFrame {
+	if g.Len() == 0 {
+		return newEmptyDataFrame()
+	}
+	groups := g.ids()
+	keep := make([]bool, len(groups))
+	values.ForEachGroup(len(groups), func(k int) {
+		keep[k] = fn(g.df.subsetFrame(g.groups[groups[k]].Positions))
+	})
+	var positions []int
+	for k := range groups {
+		if keep[k] {
+			positions = append(positions, g.groups[groups[k]].Positions...)
+		}
+	}
+	sort.Ints(positions)
+	return g.source.subsetFrame(positions)
+}
+
+// groupPositions returns the positions of each group in g.Groups() order.
+func (g Grouping) groupPositions() [][]int {
+	ids := g.ids()
+	ret := make([][]int, len(ids))
+	for k, id := range ids {
+		ret[k] = g.groups[id].Positions
+	}
+	return ret
+}
+
+// CumSum returns the cumulative sum of the non-null values of a float64 or int64 column (a label in column level 0)
+// in each group, in the original index and row order of the grouped DataFrame. Null values are null.
+// The result keeps the DataType of the column, except that CumProd is always float64.
+// An int64 sum that overflows is null, as is the rest of its group.
+func (g Grouping) CumSum(col string) (*series.Series, error) {
+	return g.accumulate("CumSum", col, "sum")
+}
+
+// CumProd returns the cumulative product of the non-null values of a float64 or int64 column in each group. See CumSum.
+func (g Grouping) CumProd(col string) (*series.Series, error) {
+	return g.accumulate("CumProd", col, "prod")
+}
+
+// CumMax returns the cumulative maximum of the non-null values of a float64 or int64 column in each group. See CumSum.
+func (g Grouping) CumMax(col string) (*series.Series, error) {
+	return g.accumulate("CumMax", col, "max")
+}
+
+// CumMin returns the cumulative minimum of the non-null values of a float64 or int64 column in each group. See CumSum.
+func (g Grouping) CumMin(col string) (*series.Series, error) {
+	return g.accumulate("CumMin", col, "min")
+}
+
+func (g Grouping) accumulate(method string, col string, op string) (*series.Series, error) {
+	positions, err := g.df.colPositions([]string{col})
+	if err != nil {
+		return series.MustNew(nil), fmt.Errorf("Grouping.%v(): %v", method, err)
+	}
+	m := positions[0]
+	if g.Len() == 0 {
+		return series.MustNew(nil), nil
+	}
+	container, err := g.df.vals[m].Accumulate(g.groupPositions(), op)
+	if err != nil {
+		return series.MustNew(nil), fmt.Errorf("Grouping.%v(): %v", method, err)
+	}
+	return series.FromInternalComponents(container, g.source.index.Copy(), g.df.cols.Name(m)), nil
+}
+
+// CumCount returns the position of each row within its group, starting at 0,
+// in the original index and row order of the grouped DataFrame (e.g., the number of earlier events for the same user).
+func (g Grouping) CumCount() *series.Series {
+	if g.Len() == 0 {
+		return series.MustNew(nil)
+	}
+	counts := make([]int64, g.df.index.Len())
+	for _, positions := range g.groupPositions() {
+		for i, pos := range positions {
+			counts[pos] = int64(i)
+		}
+	}
+	return series.FromInternalComponents(values.MustCreateValuesFromInterface(counts), g.source.index.Copy(), "cumcount")
+}
+
+// NGroup returns the number of the group of each row, starting at 0 in order of first appearance (g.Groups() order),
+// in the original index and row order of the grouped DataFrame.
+func (g Grouping) NGroup() *series.Series {
+	if g.Len() == 0 {
+		return series.MustNew(nil)
+	}
+	numbers := make([]int64, g.df.index.Len())
+	for k, positions := range g.groupPositions() {
+		for _, pos := range positions {
+			numbers[pos] = int64(k)
+		}
+	}
+	return series.FromInternalComponents(values.MustCreateValuesFromInterface(numbers), g.source.index.Copy(), "ngroup")
+}
+
+// Rank returns the ascending rank, starting at 1, of each non-null value of a column (a label in column level 0) within its group,
+// in the original index and row order of the grouped DataFrame. Null values are null.
+// Tied values share the average of their ranks, unless method is "min", "max", "first" or "dense".
+func (g Grouping) Rank(col string, method string) (*series.Series, error) {
+	positions, err := g.df.colPositions([]string{col})
+	if err != nil {
+		return series.MustNew(nil), fmt.Errorf("Grouping.Rank(): %v", err)
+	}
+	m := positions[0]
+	if g.Len() == 0 {
+		return series.MustNew(nil), nil
+	}
+	container, err := g.df.vals[m].Rank(g.groupPositions(), method)
+	if err != nil {
+		return series.MustNew(nil), fmt.Errorf("Grouping.Rank(): %v", err)
+	}
+	return series.FromInternalComponents(container, g.source.index.Copy(), g.df.cols.Name(m)), nil
 }
@@ -2,18 +2,31 @@ package dataframe
 
 import (
 	"bytes"
+	"fmt"
 	"log"
+	"math"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ptiger10/pd/options"
+	"github.com/ptiger10/pd/series"
 )
 
+// groupsByLabel returns the groups in g keyed by their label in g.Groups(), without their GroupKeys.
+func groupsByLabel(g Grouping) map[string]*group {
+	ret := make(map[string]*group)
+	for _, grp := range g.groups {
+		ret[grp.Key.String()] = &group{Positions: grp.Positions, FirstPosition: grp.FirstPosition}
+	}
+	return ret
+}
+
 func TestGroup_Copy(t *testing.T) {
 	s := MustNew([]interface{}{[]int{1, 2, 3, 4}}, Config{Index: []int{1, 1, 2, 2}})
-	got := s.GroupByIndex(0).copy().groups
+	got := groupsByLabel(s.GroupByIndex(0).copy())
 	want := map[string]*group{
 		"1": {Positions: []int{0, 1}, FirstPosition: 0},
 		"2": {Positions: []int{2, 3}, FirstPosition: 2},
@@ -85,7 +98,7 @@ func TestDataFrame_GroupByIndex(t *testing.T) {
 			defer log.SetOutput(os.Stderr)
 
 			df := tt.input.Copy()
-			got := df.GroupByIndex(tt.args.levelPositions...).groups
+			got := groupsByLabel(df.GroupByIndex(tt.args.levelPositions...))
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("DataFrame.GroupByIndex() = %v, want %v", got, tt.want)
 			}
@@ -149,7 +162,7 @@ func TestDataFrame_GroupBy(t *testing.T) {
 			defer log.SetOutput(os.Stderr)
 
 			df := tt.input.Copy()
-			got := df.GroupBy(tt.args.cols...).groups
+			got := groupsByLabel(df.GroupBy(tt.args.cols...))
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("DataFrame.GroupBy() = %#v, want %#v", got, tt.want)
 			}
@@ -267,6 +280,12 @@ func TestGrouping_Math(t *testing.T) {
 			MustNew([]interface{}{[]float64{1.5, 3.5}}, Config{Index: []int{1, 2}, Col: []string{"A"}})},
 		{"standard deviation", df, Grouping.Std,
 			MustNew([]interface{}{[]float64{0.5, 0.5}}, Config{Index: []int{1, 2}, Col: []string{"A"}})},
+		{"variance", df, Grouping.Var,
+			MustNew([]interface{}{[]float64{0.25, 0.25}}, Config{Index: []int{1, 2}, Col: []string{"A"}})},
+		{"product", df, Grouping.Prod,
+			MustNew([]interface{}{[]float64{2, 12}}, Config{Index: []int{1, 2}, Col: []string{"A"}})},
+		{"quantile", df, func(g Grouping) *DataFrame { return g.Quantile(0.25) },
+			MustNew([]interface{}{[]float64{1.25, 3.25}}, Config{Index: []int{1, 2}, Col: []string{"A"}})},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -286,3 +305,609 @@ func TestGrouping_Math(t *testing.T) {
 		})
 	}
 }
+
+func TestGrouping_typedKeys(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3, 4}},
+		Config{MultiIndex: []interface{}{[]interface{}{1, "1", "a | b", "a"}, []string{"c", "c", "c", "b | c"}}})
+	g := df.GroupByIndex()
+	if g.Len() != 4 {
+		t.Fatalf("Grouping.Len() = %d, want 4 distinct groups", g.Len())
+	}
+	wantKeys := []GroupKey{{Labels: []interface{}{1, "c"}}, {Labels: []interface{}{"1", "c"}}, {Labels: []interface{}{"a | b", "c"}}, {Labels: []interface{}{"a", "b | c"}}}
+	if got := g.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("Grouping.Keys() = %v, want %v", got, wantKeys)
+	}
+	if got := g.Groups(); !reflect.DeepEqual(got, []string{"1 | c", "1 | c", "a | b | c", "a | b | c"}) {
+		t.Errorf("Grouping.Groups() = %v, want labels in group order", got)
+	}
+
+	tests := []struct {
+		name string
+		key  []interface{}
+		want *DataFrame
+	}{
+		{"int", []interface{}{1, "c"}, df.subsetRows([]int{0})},
+		{"string", []interface{}{"1", "c"}, df.subsetRows([]int{1})},
+		{"separator in first level", []interface{}{"a | b", "c"}, df.subsetRows([]int{2})},
+		{"separator in second level", []interface{}{"a", "b | c"}, df.subsetRows([]int{3})},
+		{"fail: missing key", []interface{}{2, "c"}, newEmptyDataFrame()},
+		{"fail: too few labels", []interface{}{1}, newEmptyDataFrame()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			got := g.GroupByKey(tt.key...)
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.GroupByKey() = %v, want %v", got, tt.want)
+			}
+			if strings.Contains(tt.name, "fail") {
+				if buf.String() == "" {
+					t.Errorf("Grouping.GroupByKey() returned no log message, want log due to fail")
+				}
+			}
+		})
+	}
+}
+
+func TestGrouping_typedKeyIndex(t *testing.T) {
+	df := MustNew([]interface{}{[]float64{1, 2, 3}}, Config{Index: []int{2, 1, 2}, IndexName: "key"})
+	want := MustNew([]interface{}{[]float64{4, 2}}, Config{Index: []int{2, 1}, IndexName: "key"})
+	got := df.GroupByIndex().Sum()
+	if !Equal(got, want) {
+		t.Errorf("Grouping.Sum() = %v, want %v", got, want)
+	}
+	options.SetAsync(false)
+	gotSync := df.GroupByIndex().Sum()
+	options.RestoreDefaults()
+	if !Equal(gotSync, want) {
+		t.Errorf("Grouping.Sum() synchronous = %v, want %v", gotSync, want)
+	}
+	if got := df.GroupByIndex().GroupByKey(2); got.Len() != 2 {
+		t.Errorf("Grouping.GroupByKey() on Int64 level returned %d rows, want 2", got.Len())
+	}
+}
+
+func TestDataFrame_GroupByCols(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"foo", "bar", "foo"}, []int{1, 2, 3}}, Config{Col: []string{"A", "B"}})
+	tests := []struct {
+		name   string
+		names  []string
+		config *GroupByOptions
+		want   *DataFrame
+	}{
+		{"move keys into index", []string{"A"}, nil,
+			MustNew([]interface{}{[]float64{4, 2}}, Config{Index: []string{"foo", "bar"}, IndexName: "A", Col: []string{"B"}})},
+		{"keep keys", []string{"A"}, &GroupByOptions{KeepKeys: true},
+			MustNew([]interface{}{[]int64{2, 1}, []int64{2, 1}}, Config{Index: []string{"foo", "bar"}, IndexName: "A", Col: []string{"A", "B"}})},
+		{"keep keys: every column is a key", []string{"A", "B"}, &GroupByOptions{KeepKeys: true},
+			MustNew([]interface{}{[]int64{1, 1, 1}, []int64{1, 1, 1}},
+				Config{MultiIndex: []interface{}{[]string{"foo", "bar", "foo"}, []int{1, 2, 3}}, MultiIndexNames: []string{"A", "B"}, Col: []string{"A", "B"}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *DataFrame
+			if tt.config == nil {
+				got = df.GroupByCols(tt.names...).Reduce(series.AggSum)
+			} else {
+				got = df.GroupByColsWithOptions(tt.names, *tt.config).Count()
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.GroupByCols() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	g := MustNew([]interface{}{[]string{"foo", "bar", "foo"}, []int{1, 2, 1}}, Config{Col: []string{"A", "B"}}).
+		GroupByCols("A", "B")
+	wantSize := series.MustNew([]int64{2, 1}, series.Config{MultiIndex: []interface{}{[]string{"foo", "bar"}, []int{1, 2}},
+		MultiIndexNames: []string{"A", "B"}, Name: "size"})
+	if got := g.Size(); !series.Equal(got, wantSize) {
+		t.Errorf("DataFrame.GroupByCols() with every column as key: Size() = %v, want %v", got, wantSize)
+	}
+	wantKeys := []GroupKey{{Labels: []interface{}{"foo", int64(1)}}, {Labels: []interface{}{"bar", int64(2)}}}
+	if got := g.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("DataFrame.GroupByCols() with every column as key: Keys() = %v, want %v", got, wantKeys)
+	}
+	wantNGroup := series.MustNew([]int64{0, 1, 0}, series.Config{Name: "ngroup"})
+	if got := g.NGroup(); !series.Equal(got, wantNGroup) {
+		t.Errorf("DataFrame.GroupByCols() with every column as key: NGroup() = %v, want %v", got, wantNGroup)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	for _, names := range [][]string{nil, {"C"}} {
+		if got := df.GroupByCols(names...); got.Len() != 0 {
+			t.Errorf("DataFrame.GroupByCols(%v) returned %d groups, want empty Grouping", names, got.Len())
+		}
+	}
+	if got := df.GroupByColsWithOptions([]string{"C"}, GroupByOptions{KeepKeys: true}); got.Len() != 0 {
+		t.Errorf("DataFrame.GroupByColsWithOptions() with invalid column returned %d groups, want empty Grouping", got.Len())
+	}
+	if buf.String() == "" {
+		t.Errorf("DataFrame.GroupByCols() returned no log message, want log due to fail")
+	}
+}
+
+func TestDataFrame_GroupBySeries(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3}}, Config{Col: []string{"A"}})
+	keys := series.MustNew([]string{"foo", "bar", "foo"}, series.Config{Name: "key"})
+	got := df.GroupBySeries(keys).Reduce(series.AggSum)
+	want := MustNew([]interface{}{[]float64{4, 2}}, Config{Index: []string{"foo", "bar"}, IndexName: "key", Col: []string{"A"}})
+	if !Equal(got, want) {
+		t.Errorf("DataFrame.GroupBySeries() = %v, want %v", got, want)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	if got := df.GroupBySeries(series.MustNew([]string{"foo"})); got.Len() != 0 {
+		t.Errorf("DataFrame.GroupBySeries() with misaligned keys returned %d groups, want empty Grouping", got.Len())
+	}
+	if buf.String() == "" {
+		t.Errorf("DataFrame.GroupBySeries() returned no log message, want log due to fail")
+	}
+}
+
+func TestDataFrame_GroupByFunc(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3, 4}, []string{"foo", "bar", "baz", "qux"}}, Config{Col: []string{"A", "B"}})
+	isEven := func(r Row) interface{} { return r.Values[0].(int64)%2 == 0 }
+	got := df.GroupByFunc(isEven).Reduce(series.AggFirst)
+	want := MustNew([]interface{}{[]int{1, 2}, []string{"foo", "bar"}}, Config{Index: []bool{false, true}, Col: []string{"A", "B"}})
+	if !Equal(got, want) {
+		t.Errorf("DataFrame.GroupByFunc() = %v, want %v", got, want)
+	}
+	gotFilter := df.GroupByFunc(isEven).Filter(func(df *DataFrame) bool { return df.SelectCol("B") == 1 })
+	if gotFilter.Len() != 4 {
+		t.Errorf("DataFrame.GroupByFunc().Filter() returned %d rows, want 4", gotFilter.Len())
+	}
+	if got := newEmptyDataFrame().GroupByFunc(isEven); got.Len() != 0 {
+		t.Errorf("DataFrame.GroupByFunc() on empty DataFrame returned %d groups, want 0", got.Len())
+	}
+}
+
+func TestGrouping_nullInOneGroup(t *testing.T) {
+	df := MustNew([]interface{}{[]float64{1, 2, math.NaN(), math.NaN()}, []float64{5, 6, 7, 8}},
+		Config{Col: []string{"A", "B"}, Index: []string{"x", "x", "y", "y"}})
+	tests := []struct {
+		name string
+		fn   func(Grouping) *DataFrame
+		want *DataFrame
+	}{
+		{"median", Grouping.Median,
+			MustNew([]interface{}{[]float64{1.5, math.NaN()}, []float64{5.5, 7.5}}, Config{Col: []string{"A", "B"}, Index: []string{"x", "y"}})},
+		{"quantile", func(g Grouping) *DataFrame { return g.Quantile(0.25) },
+			MustNew([]interface{}{[]float64{1.25, math.NaN()}, []float64{5.25, 7.25}}, Config{Col: []string{"A", "B"}, Index: []string{"x", "y"}})},
+		{"sum", Grouping.Sum,
+			MustNew([]interface{}{[]float64{3, 0}, []float64{11, 15}}, Config{Col: []string{"A", "B"}, Index: []string{"x", "y"}})},
+		{"mean", Grouping.Mean,
+			MustNew([]interface{}{[]float64{1.5, math.NaN()}, []float64{5.5, 7.5}}, Config{Col: []string{"A", "B"}, Index: []string{"x", "y"}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// compares printed DataFrames because null float values are NaN
+			got := tt.fn(df.GroupByIndex())
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Grouping.%v() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_reductions(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 2, 4, 5}, []string{"foo", "", "bar", "", "baz"}},
+		Config{Col: []string{"A", "B"}, Index: []int{1, 1, 1, 2, 2}})
+	idx := Config{Col: []string{"A", "B"}, Index: []int{1, 2}}
+	tests := []struct {
+		name string
+		fn   func(Grouping) *DataFrame
+		want *DataFrame
+	}{
+		{"count", Grouping.Count, MustNew([]interface{}{[]int64{3, 2}, []int64{2, 1}}, idx)},
+		{"nunique", Grouping.NUnique, MustNew([]interface{}{[]int64{2, 2}, []int64{2, 1}}, idx)},
+		{"mode", Grouping.Mode, MustNew([]interface{}{[]int{2, 4}, []string{"foo", "baz"}}, idx)},
+		{"first valid", Grouping.FirstValid, MustNew([]interface{}{[]int{1, 4}, []string{"foo", "baz"}}, idx)},
+		{"last valid", Grouping.LastValid, MustNew([]interface{}{[]int{2, 5}, []string{"bar", "baz"}}, idx)},
+		{"nth from end", func(g Grouping) *DataFrame { return g.Nth(-1) },
+			MustNew([]interface{}{[]int{2, 5}, []string{"bar", "baz"}}, idx)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fn(df.GroupByIndex())
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping reduction = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync := tt.fn(df.GroupByIndex())
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping reduction synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+	gotSize := df.GroupByIndex().Size()
+	wantSize := series.MustNew([]int64{3, 2}, series.Config{Index: []int{1, 2}, Name: "size"})
+	if !series.Equal(gotSize, wantSize) {
+		t.Errorf("Grouping.Size() = %v, want %v", gotSize, wantSize)
+	}
+	if got := newEmptyDataFrame().GroupByIndex().Size(); !series.Equal(got, series.MustNew(nil)) {
+		t.Errorf("Grouping.Size() = %v, want empty Series", got)
+	}
+}
+
+func TestGrouping_Reduce(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3, 4}, []string{"foo", "bar", "", "baz"}},
+		Config{Col: []string{"A", "B"}, Index: []int{1, 1, 2, 2}})
+	tests := []struct {
+		name  string
+		input *DataFrame
+		agg   series.Agg
+		want  *DataFrame
+	}{
+		{"count", df, series.AggCount,
+			MustNew([]interface{}{[]int{2, 2}, []int{2, 1}}, Config{Col: []string{"A", "B"}, Index: []int{1, 2}})},
+		{"last", df, series.AggLast,
+			MustNew([]interface{}{[]int{2, 4}, []string{"bar", "baz"}}, Config{Col: []string{"A", "B"}, Index: []int{1, 2}})},
+		{"custom", df, series.NewAgg("len", func(s *series.Series) interface{} { return s.Len() }),
+			MustNew([]interface{}{[]int{2, 2}, []int{2, 2}}, Config{Col: []string{"A", "B"}, Index: []int{1, 2}})},
+		{"empty", newEmptyDataFrame(), series.AggCount, newEmptyDataFrame()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.GroupByIndex().Reduce(tt.agg)
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.Reduce() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync := tt.input.GroupByIndex().Reduce(tt.agg)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Reduce() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_Agg(t *testing.T) {
+	jan1, jan2, jan3 := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC)
+	df := MustNew([]interface{}{[]float64{1, 2, 3, 4}, []time.Time{jan1, jan2, jan3, {}}, []string{"foo", "bar", "baz", "qux"}},
+		Config{Col: []string{"revenue", "date", "name"}, Index: []string{"a", "a", "b", "b"}})
+	specs := map[string][]series.Agg{
+		"date":    {series.AggCount, series.AggMax},
+		"revenue": {series.AggSum},
+	}
+	type args struct {
+		specs  map[string][]series.Agg
+		config []AggOptions
+	}
+	tests := []struct {
+		name    string
+		input   *DataFrame
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "flat labels", input: df, args: args{specs, nil},
+			want: MustNew([]interface{}{[]float64{3, 7}, []int64{2, 1}, []time.Time{jan2, jan3}},
+				Config{Col: []string{"revenue_sum", "date_count", "date_max"}, Index: []string{"a", "b"}}),
+			wantErr: false},
+		{"multi level", df, args{specs, []AggOptions{{MultiLevel: true}}},
+			MustNew([]interface{}{[]float64{3, 7}, []int64{2, 1}, []time.Time{jan2, jan3}},
+				Config{MultiCol: [][]string{{"revenue", "date", "date"}, {"sum", "count", "max"}}, Index: []string{"a", "b"}}),
+			false},
+		{"empty grouping", newEmptyDataFrame(), args{specs, nil}, newEmptyDataFrame(), false},
+		{"fail: missing column", df, args{map[string][]series.Agg{"foo": {series.AggSum}}, nil}, newEmptyDataFrame(), true},
+		{"fail: no specs", df, args{nil, nil}, newEmptyDataFrame(), true},
+		{"fail: no aggregations", df, args{map[string][]series.Agg{"revenue": nil}, nil}, newEmptyDataFrame(), true},
+		{"fail: unnamed aggregation", df, args{map[string][]series.Agg{"revenue": {{}}}, nil}, newEmptyDataFrame(), true},
+		{"fail: duplicate aggregation", df, args{map[string][]series.Agg{"revenue": {series.AggSum, series.AggSum}}, nil}, newEmptyDataFrame(), true},
+		{"fail: multiple configs", df, args{specs, []AggOptions{{}, {}}}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.GroupByIndex().Agg(tt.args.specs, tt.args.config...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Grouping.Agg() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.Agg() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync, _ := tt.input.GroupByIndex().Agg(tt.args.specs, tt.args.config...)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Agg() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_Each(t *testing.T) {
+	tm := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	ints := MustNew([]interface{}{[]int{1, 2, 3, 4}}, Config{Index: []int{10, 9, 2, 10}})
+	tests := []struct {
+		name   string
+		input  *DataFrame
+		config []EachOptions
+		want   []string
+	}{
+		{"default", ints, nil, []string{"10", "9", "2"}},
+		{"first", ints, []EachOptions{{Sort: "first"}}, []string{"10", "9", "2"}},
+		{"sorted", ints, []EachOptions{{Sort: "sorted"}}, []string{"10", "2", "9"}},
+		{"natural int", ints, []EachOptions{{Sort: "natural"}}, []string{"2", "9", "10"}},
+		{"natural float with null last",
+			MustNew([]interface{}{[]int{1, 2, 3}}, Config{Index: []float64{10.5, math.NaN(), -1}}),
+			[]EachOptions{{Sort: "natural"}}, []string{"-1", "10.5", "NaN"}},
+		{"natural datetime",
+			MustNew([]interface{}{[]int{1, 2}}, Config{Index: []time.Time{tm.AddDate(1, 0, 0), tm}}),
+			[]EachOptions{{Sort: "natural"}}, []string{tm.String(), tm.AddDate(1, 0, 0).String()}},
+		{"natural multi level",
+			MustNew([]interface{}{[]int{1, 2, 3}}, Config{MultiIndex: []interface{}{[]string{"b", "a", "a"}, []int{1, 10, 9}}}),
+			[]EachOptions{{Sort: "natural"}}, []string{"a | 9", "a | 10", "b | 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := tt.input.GroupByIndex().Each(func(key GroupKey, df *DataFrame) error {
+				got = append(got, key.String())
+				return nil
+			}, tt.config...)
+			if err != nil {
+				t.Errorf("Grouping.Each() error = %v, want nil", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Grouping.Each() visited %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var gotGroup *DataFrame
+	ints.GroupByIndex().Each(func(key GroupKey, df *DataFrame) error {
+		if reflect.DeepEqual(key.Labels, []interface{}{int64(10)}) {
+			gotGroup = df
+		}
+		return nil
+	})
+	if wantGroup := ints.subsetRows([]int{0, 3}); !Equal(gotGroup, wantGroup) {
+		t.Errorf("Grouping.Each() group = %v, want %v", gotGroup, wantGroup)
+	}
+
+	var visited int
+	err := ints.GroupByIndex().Each(func(key GroupKey, df *DataFrame) error {
+		visited++
+		return fmt.Errorf("stop")
+	})
+	if err == nil || visited != 1 {
+		t.Errorf("Grouping.Each() error = %v after %d groups, want error after 1 group", err, visited)
+	}
+	noop := func(GroupKey, *DataFrame) error { return nil }
+	if err := ints.GroupByIndex().Each(noop, EachOptions{Sort: "random"}); err == nil {
+		t.Errorf("Grouping.Each() with unsupported Sort returned nil error, want error")
+	}
+	if err := ints.GroupByIndex().Each(noop, EachOptions{}, EachOptions{}); err == nil {
+		t.Errorf("Grouping.Each() with multiple configs returned nil error, want error")
+	}
+}
+
+func TestGrouping_Apply(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3, 4}, []string{"foo", "bar", "baz", "qux"}},
+		Config{Col: []string{"A", "B"}, Index: []string{"a", "a", "b", "c"}, IndexName: "key"})
+	head := func(key GroupKey, df *DataFrame) (*DataFrame, error) {
+		return df.Head(1), nil
+	}
+	tests := []struct {
+		name    string
+		input   *DataFrame
+		fn      func(GroupKey, *DataFrame) (*DataFrame, error)
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "head", input: df, fn: head,
+			want: MustNew([]interface{}{[]int{1, 3, 4}, []string{"foo", "baz", "qux"}},
+				Config{MultiIndex: []interface{}{[]string{"a", "b", "c"}, []string{"a", "b", "c"}}, MultiIndexNames: []string{"key", "key"},
+					Col: []string{"A", "B"}}),
+			wantErr: false},
+		{"key and mixed DataTypes", df, func(key GroupKey, df *DataFrame) (*DataFrame, error) {
+			if key.String() == "a" {
+				return nil, nil
+			}
+			if key.Labels[0] == "b" {
+				return MustNew([]interface{}{[]float64{0.5}}, Config{Col: []string{"A"}, Index: "x"}), nil
+			}
+			return MustNew([]interface{}{[]int{1}}, Config{Col: []string{"A"}, Index: "y"}), nil
+		},
+			MustNew([]interface{}{[]float64{0.5, 1}},
+				Config{MultiIndex: []interface{}{[]string{"b", "c"}, []string{"x", "y"}}, MultiIndexNames: []string{"key", ""}, Col: []string{"A"}}),
+			false},
+		{"all empty", df, func(GroupKey, *DataFrame) (*DataFrame, error) { return newEmptyDataFrame(), nil }, newEmptyDataFrame(), false},
+		{"empty grouping", newEmptyDataFrame(), head, newEmptyDataFrame(), false},
+		{"fail: errors", df, func(key GroupKey, df *DataFrame) (*DataFrame, error) {
+			if key.String() != "a" {
+				return nil, fmt.Errorf("foo")
+			}
+			return df, nil
+		}, newEmptyDataFrame(), true},
+		{"fail: different columns", df, func(key GroupKey, df *DataFrame) (*DataFrame, error) {
+			if key.String() != "a" {
+				return df.Head(1).Transpose(), nil
+			}
+			return df, nil
+		}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.GroupByIndex().Apply(tt.fn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Grouping.Apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.Apply() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync, _ := tt.input.GroupByIndex().Apply(tt.fn)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Apply() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_Apply_errors(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3}}, Config{Index: []string{"a", "b", "c"}})
+	_, err := df.GroupByIndex().Apply(func(key GroupKey, df *DataFrame) (*DataFrame, error) {
+		return nil, fmt.Errorf("failed %v", key)
+	})
+	want := "Grouping.Apply(): 3 errors: group a: failed a; group b: failed b; group c: failed c"
+	if err == nil || err.Error() != want {
+		t.Errorf("Grouping.Apply() error = %v, want %v", err, want)
+	}
+}
+
+func TestGrouping_Transform(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"a", "b", "a", "b"}, []float64{1, 2, 3, 5}},
+		Config{Col: []string{"customer", "amount"}, Index: []int{10, 11, 12, 13}})
+	share := func(s *series.Series) *series.Series {
+		total := s.Sum()
+		ret := make([]float64, s.Len())
+		for i, v := range s.Vals().([]float64) {
+			ret[i] = v / total
+		}
+		return series.MustNew(ret)
+	}
+	tests := []struct {
+		name    string
+		col     string
+		fn      func(*series.Series) *series.Series
+		want    *series.Series
+		wantErr bool
+	}{
+		{name: "reduction", col: "amount", fn: func(s *series.Series) *series.Series { return series.MustNew(s.Sum()) },
+			want:    series.MustNew([]float64{4, 7, 4, 7}, series.Config{Index: []int{10, 11, 12, 13}, Name: "amount"}),
+			wantErr: false},
+		{"vector", "amount", share,
+			series.MustNew([]float64{0.25, 2.0 / 7, 0.75, 5.0 / 7}, series.Config{Index: []int{10, 11, 12, 13}, Name: "amount"}),
+			false},
+		{"fail: missing column", "foo", share, series.MustNew(nil), true},
+		{"fail: wrong length", "amount", func(*series.Series) *series.Series { return series.MustNew([]int{1, 2, 3}) }, series.MustNew(nil), true},
+		{"fail: nil", "amount", func(*series.Series) *series.Series { return nil }, series.MustNew(nil), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := df.GroupBy(0).Transform(tt.col, tt.fn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Grouping.Transform() error = %v, want %v", err, tt.wantErr)
+			}
+			if !series.Equal(got, tt.want) {
+				t.Errorf("Grouping.Transform() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync, _ := df.GroupBy(0).Transform(tt.col, tt.fn)
+			options.RestoreDefaults()
+			if !series.Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Transform() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_TransformAgg(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3}}, Config{Col: []string{"A"}, Index: []string{"a", "b", "a"}})
+	got, err := df.GroupByIndex().TransformAgg("A", series.AggMax)
+	if err != nil {
+		t.Errorf("Grouping.TransformAgg() error: %v", err)
+	}
+	want := series.MustNew([]float64{3, 2, 3}, series.Config{Index: []string{"a", "b", "a"}, Name: "A"})
+	if !series.Equal(got, want) {
+		t.Errorf("Grouping.TransformAgg() = %v, want %v", got, want)
+	}
+}
+
+func TestGrouping_Filter(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"a", "b", "a", "c", "a"}, []int{1, 2, 3, 4, 5}},
+		Config{Col: []string{"customer", "order"}, Index: []int{10, 11, 12, 13, 14}})
+	tests := []struct {
+		name  string
+		input *DataFrame
+		fn    func(*DataFrame) bool
+		want  *DataFrame
+	}{
+		{"keep some", df, func(df *DataFrame) bool { return df.Len() > 2 },
+			MustNew([]interface{}{[]string{"a", "a", "a"}, []int{1, 3, 5}}, Config{Col: []string{"customer", "order"}, Index: []int{10, 12, 14}})},
+		{"keep all", df, func(*DataFrame) bool { return true }, df},
+		{"keep none", df, func(*DataFrame) bool { return false }, df.subsetFrame(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.GroupBy(0).Filter(tt.fn)
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.Filter() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync := tt.input.GroupBy(0).Filter(tt.fn)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Filter() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+	if got := newEmptyDataFrame().GroupByIndex().Filter(func(*DataFrame) bool { return true }); !Equal(got, newEmptyDataFrame()) {
+		t.Errorf("Grouping.Filter() = %v, want empty DataFrame", got)
+	}
+}
+
+func TestGrouping_cumulative(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"bob", "amy", "bob", "amy", "bob"}, []float64{3, 2, 1, 4, 5}, []int{1, 2, 3, 4, 5}},
+		Config{Col: []string{"user", "amount", "qty"}, Index: []int{10, 11, 12, 13, 14}})
+	g := df.GroupByCols("user")
+	tests := []struct {
+		name string
+		fn   func(Grouping) (*series.Series, error)
+		want *series.Series
+	}{
+		{"sum", func(g Grouping) (*series.Series, error) { return g.CumSum("amount") },
+			series.MustNew([]float64{3, 2, 4, 6, 9}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "amount"})},
+		{"int64 sum", func(g Grouping) (*series.Series, error) { return g.CumSum("qty") },
+			series.MustNew([]int64{1, 2, 4, 6, 9}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "qty"})},
+		{"int64 prod", func(g Grouping) (*series.Series, error) { return g.CumProd("qty") },
+			series.MustNew([]float64{1, 2, 3, 8, 15}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "qty"})},
+		{"prod", func(g Grouping) (*series.Series, error) { return g.CumProd("amount") },
+			series.MustNew([]float64{3, 2, 3, 8, 15}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "amount"})},
+		{"max", func(g Grouping) (*series.Series, error) { return g.CumMax("amount") },
+			series.MustNew([]float64{3, 2, 3, 4, 5}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "amount"})},
+		{"min", func(g Grouping) (*series.Series, error) { return g.CumMin("amount") },
+			series.MustNew([]float64{3, 2, 1, 2, 1}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "amount"})},
+		{"rank", func(g Grouping) (*series.Series, error) { return g.Rank("amount", "dense") },
+			series.MustNew([]float64{2, 1, 1, 2, 3}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "amount"})},
+		{"cumcount", func(g Grouping) (*series.Series, error) { return g.CumCount(), nil },
+			series.MustNew([]int64{0, 0, 1, 1, 2}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "cumcount"})},
+		{"ngroup", func(g Grouping) (*series.Series, error) { return g.NGroup(), nil },
+			series.MustNew([]int64{0, 1, 0, 1, 0}, series.Config{Index: []int{10, 11, 12, 13, 14}, Name: "ngroup"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(g)
+			if err != nil {
+				t.Errorf("Grouping cumulative operation error = %v, want nil", err)
+			}
+			if !series.Equal(got, tt.want) {
+				t.Errorf("Grouping cumulative operation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := g.CumSum("user"); err == nil {
+		t.Errorf("Grouping.CumSum() on string column returned nil error, want error")
+	}
+	if _, err := g.CumSum("missing"); err == nil {
+		t.Errorf("Grouping.CumSum() on missing column returned nil error, want error")
+	}
+	if _, err := g.Rank("missing", ""); err == nil {
+		t.Errorf("Grouping.Rank() on missing column returned nil error, want error")
+	}
+	if _, err := g.Rank("amount", "random"); err == nil {
+		t.Errorf("Grouping.Rank() with unsupported method returned nil error, want error")
+	}
+}
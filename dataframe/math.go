@@ -1,6 +1,7 @@
 package dataframe
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/ptiger10/pd/series"
@@ -58,3 +59,18 @@ func (df *DataFrame) Max() *series.Series {
 func (df *DataFrame) Std() *series.Series {
 	return df.math("std", (*series.Series).Std)
 }
+
+// Var returns the variance of all numerical columns.
+func (df *DataFrame) Var() *series.Series {
+	return df.math("var", (*series.Series).Var)
+}
+
+// Prod returns the product of all numerical columns.
+func (df *DataFrame) Prod() *series.Series {
+	return df.math("prod", (*series.Series).Prod)
+}
+
+// Quantile returns the quantile q of all numerical columns (see series.Quantile).
+func (df *DataFrame) Quantile(q float64) *series.Series {
+	return df.math(fmt.Sprintf("quantile_%v", q), func(s *series.Series) float64 { return s.Quantile(q) })
+}
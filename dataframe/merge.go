@@ -1,5 +1,17 @@
 package dataframe
 
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/ptiger10/pd/internal/index"
+	"github.com/ptiger10/pd/internal/values"
+	"github.com/ptiger10/pd/options"
+	"github.com/ptiger10/pd/series"
+)
+
 // // Join extends the columns, rows, or columns and rows of a dataframe by appending s2 and modifies the DataFrame in place.
 // // If extending rows, the values within a Values container are converted to []interface if the container datatypes are not the same.
 // //
@@ -42,7 +54,6 @@ func (ip InPlace) appendDataFrameRow(df2 *DataFrame) {
 	return
 }
 
-
 func (ip InPlace) appendDataFrameColumn(df2 *DataFrame) error {
 	// Handling empty DataFrame
 	if Equal(ip.df, newEmptyDataFrame()) {
@@ -72,3 +83,393 @@ func (ip InPlace) appendDataFrameColumn(df2 *DataFrame) error {
 // 	df.InPlace.Join(append, method, df2)
 // 	return df
 // }
+
+// LookupColumns performs a vlookup of each index label in df against the index labels of df2
+// and returns a new DataFrame with the index of df and the matching rows from every column in df2.
+// Labels are matched by type and value across all index levels.
+// Labels that are not in df2 are filled with config.Default if supplied, or null otherwise.
+func (df *DataFrame) LookupColumns(df2 *DataFrame, config ...series.LookupConfig) *DataFrame {
+	ret, err := df.lookupColumns(df2, config...)
+	if err != nil {
+		if options.GetLogWarnings() {
+			log.Printf("DataFrame.LookupColumns(): %v\n", err)
+		}
+		return newEmptyDataFrame()
+	}
+	return ret
+}
+
+func (df *DataFrame) lookupColumns(df2 *DataFrame, config ...series.LookupConfig) (*DataFrame, error) {
+	if len(config) > 1 {
+		return nil, fmt.Errorf("can supply at most one LookupConfig (%d > 1)", len(config))
+	}
+	var cfg series.LookupConfig
+	if len(config) == 1 {
+		cfg = config[0]
+	}
+	if cfg.Default != nil {
+		if _, err := values.InterfaceFactory(cfg.Default); err != nil {
+			return nil, fmt.Errorf("invalid Default: %v", err)
+		}
+	}
+	positions, err := df.index.LookupPositions(df2.index, cfg.Duplicates)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]values.Container, df2.NumCols())
+	for m := 0; m < df2.NumCols(); m++ {
+		vals[m] = df2.vals[m].Lookup(positions)
+		if cfg.Default != nil {
+			for i, pos := range positions {
+				if pos == -1 {
+					vals[m].Values.Set(i, cfg.Default)
+				}
+			}
+		}
+	}
+	return newFromComponents(vals, df.index.Copy(), df2.cols.Copy(), df2.name), nil
+}
+
+// patch converts the values of src to the DataType of dst and writes them into dst at every row i where srcPositions[i] != -1,
+// the value in src is not null, and write(i) returns true.
+// If any value cannot be converted, or would lose its fractional part in conversion from float64 to int64,
+// dst is not modified and a description of each conflict is returned.
+func patch(dst, src values.Container, srcPositions []int, write func(i int) bool, describe func(i int) string) []string {
+	converted := src.Values
+	if src.DataType != dst.DataType {
+		// ducks error because dst has a valid DataType
+		converted, _ = values.Convert(src.Values, dst.DataType)
+	}
+	var rows []int
+	var conflicts []string
+	for i, pos := range srcPositions {
+		if pos == -1 || src.Values.Null(pos) || !write(i) {
+			continue
+		}
+		if converted.Null(pos) || truncates(src, dst.DataType, pos) {
+			conflicts = append(conflicts, fmt.Sprintf("%v: %v (%v) -> %v", describe(i), src.Values.Value(pos), src.DataType, dst.DataType))
+			continue
+		}
+		rows = append(rows, i)
+	}
+	if len(conflicts) > 0 {
+		return conflicts
+	}
+	for _, i := range rows {
+		dst.Values.Set(i, converted.Value(srcPositions[i]))
+	}
+	return nil
+}
+
+// truncates returns true if the value at pos in src is a float64 that cannot be written into an int64 container without loss.
+func truncates(src values.Container, dataType options.DataType, pos int) bool {
+	if src.DataType != options.Float64 || dataType != options.Int64 {
+		return false
+	}
+	f := src.Values.Value(pos).(float64)
+	return f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64
+}
+
+// describeCell returns a description of a cell by its row and column labels.
+func describeCell(idx index.Index, cols index.Columns, row, col int) string {
+	return fmt.Sprintf("[row %v, col %v]", idx.Elements(row).Labels, cols.MultiName(col))
+}
+
+// Update writes the non-null values in other into df wherever the index and column labels match, and modifies the DataFrame in place.
+// Index labels are matched by type and value across all levels, and column labels across all levels.
+// If overwrite is false, only null values in df are updated.
+// Values are converted to the DataType of the column in df.
+// If any value cannot be converted (including float64 values with a fractional part written into an int64 column), or if other has duplicate index labels, returns an error and df is not modified.
+func (ip InPlace) Update(other *DataFrame, overwrite bool) error {
+	rowPositions, err := ip.df.index.LookupPositions(other.index, "error")
+	if err != nil {
+		return fmt.Errorf("DataFrame.Update(): %v", err)
+	}
+	_, colPositions, otherColPositions, err := ip.df.cols.Align(other.cols)
+	if err != nil {
+		return fmt.Errorf("DataFrame.Update(): %v", err)
+	}
+	vals := make([]values.Container, ip.df.NumCols())
+	var conflicts []string
+	for k, m := range colPositions {
+		if m == -1 {
+			break
+		}
+		vals[m] = ip.df.vals[m].Copy()
+		c := otherColPositions[k]
+		if c == -1 {
+			continue
+		}
+		dst := vals[m]
+		write := func(i int) bool { return overwrite || dst.Values.Null(i) }
+		describe := func(i int) string { return describeCell(ip.df.index, ip.df.cols, i, m) }
+		conflicts = append(conflicts, patch(dst, other.vals[c], rowPositions, write, describe)...)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("DataFrame.Update(): %d value(s) could not be converted: %v", len(conflicts), strings.Join(conflicts, "; "))
+	}
+	ip.df.vals = vals
+	return nil
+}
+
+// Update writes the non-null values in other into df wherever the index and column labels match, and returns a new DataFrame.
+// See InPlace.Update for details.
+func (df *DataFrame) Update(other *DataFrame, overwrite bool) (*DataFrame, error) {
+	df = df.Copy()
+	err := df.InPlace.Update(other, overwrite)
+	return df, err
+}
+
+// CombineFirst fills the null values in df with the values in other that have the same index and column labels, and returns a new DataFrame
+// with the union of the index and column labels in df and other.
+// Rows and columns in df come first in their original order, followed by those only in other.
+// Columns retain their DataType in df, or in other for columns only in other.
+// If any value in other cannot be converted to the DataType of the column in df, returns an error.
+func (df *DataFrame) CombineFirst(other *DataFrame) (*DataFrame, error) {
+	idx, rowPositions, otherRowPositions, err := df.index.Align(other.index)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.CombineFirst(): %v", err)
+	}
+	cols, colPositions, otherColPositions, err := df.cols.Align(other.cols)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.CombineFirst(): %v", err)
+	}
+	vals := make([]values.Container, cols.Len())
+	var conflicts []string
+	for k := range vals {
+		m, c := colPositions[k], otherColPositions[k]
+		if m == -1 {
+			vals[k] = other.vals[c].Lookup(otherRowPositions)
+			continue
+		}
+		vals[k] = df.vals[m].Lookup(rowPositions)
+		if c == -1 {
+			continue
+		}
+		dst := vals[k]
+		write := func(i int) bool { return dst.Values.Null(i) }
+		describe := func(i int) string { return describeCell(idx, cols, i, k) }
+		conflicts = append(conflicts, patch(dst, other.vals[c], otherRowPositions, write, describe)...)
+	}
+	if len(conflicts) > 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.CombineFirst(): %d value(s) could not be converted: %v",
+			len(conflicts), strings.Join(conflicts, "; "))
+	}
+	return newFromComponents(vals, idx, cols, df.name), nil
+}
+
+// rowKey returns a string that uniquely identifies the typed values in the specified columns of a row, for use as a hash key.
+func (df *DataFrame) rowKey(row int, cols []int) string {
+	var key string
+	for _, m := range cols {
+		key += values.Key(df.vals[m].Values.Value(row))
+	}
+	return key
+}
+
+// rowValues returns the values in the specified columns of a row.
+func (df *DataFrame) rowValues(row int, cols []int) []interface{} {
+	vals := make([]interface{}, len(cols))
+	for i, m := range cols {
+		vals[i] = df.vals[m].Values.Value(row)
+	}
+	return vals
+}
+
+// keyTable maps the key of every row to the rows with that key, and returns the keys in order of first appearance.
+func (df *DataFrame) keyTable(cols []int) (map[string][]int, []string) {
+	table := make(map[string][]int)
+	var order []string
+	for i := 0; i < df.Len(); i++ {
+		key := df.rowKey(i, cols)
+		if _, ok := table[key]; !ok {
+			order = append(order, key)
+		}
+		table[key] = append(table[key], i)
+	}
+	return table, order
+}
+
+// duplicateKeys returns the values of every key that appears in more than one row.
+func (df *DataFrame) duplicateKeys(cols []int, table map[string][]int, order []string) [][]interface{} {
+	var ret [][]interface{}
+	for _, key := range order {
+		if rows := table[key]; len(rows) > 1 {
+			ret = append(ret, df.rowValues(rows[0], cols))
+		}
+	}
+	return ret
+}
+
+// Merge joins the rows of df and right that have the same values in the key columns and returns a new DataFrame
+// with a default index. The result contains the key columns, followed by the other columns in df, then the other columns in right.
+// Values are matched by type and value. Multi-level column labels are joined into a single level.
+//
+// If config.Validate is set and the keys in either DataFrame are not unique as required,
+// returns an error listing the duplicate keys.
+func (df *DataFrame) Merge(right *DataFrame, config MergeOptions) (*DataFrame, error) {
+	how := config.How
+	if how == "" {
+		how = "inner"
+	}
+	if how != "inner" && how != "left" && how != "right" && how != "outer" {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Merge(): How must be inner, left, right, or outer, not %q", how)
+	}
+	if len(config.On) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Merge(): must supply at least one key column in On")
+	}
+	suffixes := config.Suffixes
+	if suffixes == [2]string{} {
+		suffixes = [2]string{"_x", "_y"}
+	}
+	leftKeys, err := df.colPositions(config.On)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Merge(): left: %v", err)
+	}
+	rightKeys, err := right.colPositions(config.On)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Merge(): right: %v", err)
+	}
+	leftTable, leftOrder := df.keyTable(leftKeys)
+	rightTable, rightOrder := right.keyTable(rightKeys)
+
+	// validation
+	var uniqueLeft, uniqueRight bool
+	switch config.Validate {
+	case "":
+	case "one_to_one":
+		uniqueLeft, uniqueRight = true, true
+	case "one_to_many":
+		uniqueLeft = true
+	case "many_to_one":
+		uniqueRight = true
+	default:
+		return newEmptyDataFrame(), fmt.Errorf(
+			"DataFrame.Merge(): Validate must be one_to_one, one_to_many, many_to_one, or empty, not %q", config.Validate)
+	}
+	if uniqueLeft {
+		if dupes := df.duplicateKeys(leftKeys, leftTable, leftOrder); len(dupes) > 0 {
+			return newEmptyDataFrame(), fmt.Errorf(
+				"DataFrame.Merge(): keys %v are not unique in left DataFrame, so merge is not %v: duplicate keys: %v",
+				config.On, config.Validate, dupes)
+		}
+	}
+	if uniqueRight {
+		if dupes := right.duplicateKeys(rightKeys, rightTable, rightOrder); len(dupes) > 0 {
+			return newEmptyDataFrame(), fmt.Errorf(
+				"DataFrame.Merge(): keys %v are not unique in right DataFrame, so merge is not %v: duplicate keys: %v",
+				config.On, config.Validate, dupes)
+		}
+	}
+
+	// matching rows
+	var leftPositions, rightPositions []int
+	if how == "right" {
+		for j := 0; j < right.Len(); j++ {
+			matches := leftTable[right.rowKey(j, rightKeys)]
+			if len(matches) == 0 {
+				leftPositions = append(leftPositions, -1)
+				rightPositions = append(rightPositions, j)
+			}
+			for _, i := range matches {
+				leftPositions = append(leftPositions, i)
+				rightPositions = append(rightPositions, j)
+			}
+		}
+	} else {
+		matched := make([]bool, right.Len())
+		for i := 0; i < df.Len(); i++ {
+			matches := rightTable[df.rowKey(i, leftKeys)]
+			if len(matches) == 0 && how != "inner" {
+				leftPositions = append(leftPositions, i)
+				rightPositions = append(rightPositions, -1)
+			}
+			for _, j := range matches {
+				leftPositions = append(leftPositions, i)
+				rightPositions = append(rightPositions, j)
+				matched[j] = true
+			}
+		}
+		if how == "outer" {
+			for j := 0; j < right.Len(); j++ {
+				if !matched[j] {
+					leftPositions = append(leftPositions, -1)
+					rightPositions = append(rightPositions, j)
+				}
+			}
+		}
+	}
+
+	// columns
+	var vals []values.Container
+	var labels []string
+	leftNames, rightNames := df.cols.Names(), right.cols.Names()
+	for k, m := range leftKeys {
+		container := df.vals[m].Lookup(leftPositions)
+		write := func(i int) bool { return leftPositions[i] == -1 }
+		describe := func(i int) string { return fmt.Sprintf("[row %d, col %v]", i, config.On[k]) }
+		if conflicts := patch(container, right.vals[rightKeys[k]], rightPositions, write, describe); len(conflicts) > 0 {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.Merge(): %d key value(s) could not be converted: %v",
+				len(conflicts), strings.Join(conflicts, "; "))
+		}
+		vals = append(vals, container)
+		labels = append(labels, config.On[k])
+	}
+	isKey := func(positions []int, m int) bool {
+		for _, pos := range positions {
+			if pos == m {
+				return true
+			}
+		}
+		return false
+	}
+	leftOther, rightOther := make(map[string]bool), make(map[string]bool)
+	for m, name := range leftNames {
+		leftOther[name] = leftOther[name] || !isKey(leftKeys, m)
+	}
+	for m, name := range rightNames {
+		rightOther[name] = rightOther[name] || !isKey(rightKeys, m)
+	}
+	for m, name := range leftNames {
+		if isKey(leftKeys, m) {
+			continue
+		}
+		if rightOther[name] {
+			name += suffixes[0]
+		}
+		vals = append(vals, df.vals[m].Lookup(leftPositions))
+		labels = append(labels, name)
+	}
+	for m, name := range rightNames {
+		if isKey(rightKeys, m) {
+			continue
+		}
+		if leftOther[name] {
+			name += suffixes[1]
+		}
+		vals = append(vals, right.vals[m].Lookup(rightPositions))
+		labels = append(labels, name)
+	}
+	if config.Indicator {
+		for _, label := range labels {
+			if label == "_merge" {
+				return newEmptyDataFrame(), fmt.Errorf("DataFrame.Merge(): cannot use Indicator because a column is already labeled _merge")
+			}
+		}
+		indicator := make([]string, len(leftPositions))
+		for i := range indicator {
+			switch {
+			case leftPositions[i] == -1:
+				indicator[i] = "right_only"
+			case rightPositions[i] == -1:
+				indicator[i] = "left_only"
+			default:
+				indicator[i] = "both"
+			}
+		}
+		vals = append(vals, values.MustCreateValuesFromInterface(indicator))
+		labels = append(labels, "_merge")
+	}
+	cols := index.NewColumns(index.NewColLevel(labels, ""))
+	return newFromComponents(vals, index.NewDefault(len(leftPositions)), cols, df.name), nil
+}
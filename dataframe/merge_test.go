@@ -1,6 +1,14 @@
 package dataframe
 
-import "testing"
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ptiger10/pd/series"
+)
 
 func TestMerge_appendDataFrameRow(t *testing.T) {
 	type args struct {
@@ -72,3 +80,213 @@ func TestMerge_appendDataFrameColumn(t *testing.T) {
 		})
 	}
 }
+
+func TestDataFrame_LookupColumns(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"foo", "bar"}}, Config{Index: []int{1, 2}, Col: []string{"A"}})
+	df2 := MustNew([]interface{}{[]string{"baz", "qux", "quux"}, []int{10, 20, 30}},
+		Config{Index: []int{1, 1, 3}, Col: []string{"B", "C"}})
+	type args struct {
+		df2    *DataFrame
+		config []series.LookupConfig
+	}
+	tests := []struct {
+		name     string
+		args     args
+		want     *DataFrame
+		wantFail bool
+	}{
+		{name: "pass", args: args{df2: MustNew([]interface{}{[]string{"baz", "qux"}}, Config{Index: []int{3, 1}, Col: []string{"B"}})},
+			want:     MustNew([]interface{}{[]string{"qux", ""}}, Config{Index: []int{1, 2}, Col: []string{"B"}}),
+			wantFail: false},
+		{"last with default", args{df2, []series.LookupConfig{{Duplicates: "last", Default: 0}}},
+			MustNew([]interface{}{[]string{"qux", "0"}, []int{20, 0}}, Config{Index: []int{1, 2}, Col: []string{"B", "C"}}),
+			false},
+		{"fail: duplicates", args{df2, []series.LookupConfig{{Duplicates: "error"}}},
+			newEmptyDataFrame(), true},
+		{"fail: multiple configs", args{df2, []series.LookupConfig{{}, {}}},
+			newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			got := df.LookupColumns(tt.args.df2, tt.args.config...)
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.LookupColumns() = %v, want %v", got, tt.want)
+			}
+			if tt.wantFail {
+				if buf.String() == "" {
+					t.Errorf("DataFrame.LookupColumns() returned no log message, want log due to fail")
+				}
+			}
+		})
+	}
+}
+
+func TestDataFrame_Update(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"foo", "", "baz"}, []int{1, 2, 3}}, Config{Index: []int{1, 2, 3}, Col: []string{"A", "B"}})
+	other := MustNew([]interface{}{[]float64{20, 40}, []string{"qux", "quux"}, []string{"corge", "waldo"}},
+		Config{Index: []int{2, 4}, Col: []string{"B", "A", "C"}})
+	type args struct {
+		other     *DataFrame
+		overwrite bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "overwrite", args: args{other, true},
+			want:    MustNew([]interface{}{[]string{"foo", "qux", "baz"}, []int{1, 20, 3}}, Config{Index: []int{1, 2, 3}, Col: []string{"A", "B"}}),
+			wantErr: false},
+		{"nulls only", args{other, false},
+			MustNew([]interface{}{[]string{"foo", "qux", "baz"}, []int{1, 2, 3}}, Config{Index: []int{1, 2, 3}, Col: []string{"A", "B"}}),
+			false},
+		{"null values in other are ignored", args{MustNew([]interface{}{[]string{""}}, Config{Index: []int{1}, Col: []string{"A"}}), true},
+			df, false},
+		{"fail: conversion conflict", args{MustNew([]interface{}{[]string{"corge"}}, Config{Index: []int{1}, Col: []string{"B"}}), true},
+			df, true},
+		{"fail: duplicate labels in other", args{MustNew([]interface{}{[]string{"corge", "waldo"}}, Config{Index: []int{1, 1}, Col: []string{"A"}}), true},
+			df, true},
+		{"whole float into int", args{MustNew([]interface{}{[]float64{20}}, Config{Index: []int{2}, Col: []string{"B"}}), true},
+			MustNew([]interface{}{[]string{"foo", "", "baz"}, []int{1, 20, 3}}, Config{Index: []int{1, 2, 3}, Col: []string{"A", "B"}}),
+			false},
+		{"fail: fractional float into int", args{MustNew([]interface{}{[]float64{20, 2.7}}, Config{Index: []int{1, 2}, Col: []string{"B"}}), true},
+			df, true},
+		{"fail: float out of int range", args{MustNew([]interface{}{[]float64{1e19}}, Config{Index: []int{1}, Col: []string{"B"}}), true},
+			df, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dfArchive := df.Copy()
+			got, err := df.Update(tt.args.other, tt.args.overwrite)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.Update() got %v, want %v", got, tt.want)
+			}
+			if !Equal(df, dfArchive) {
+				t.Errorf("DataFrame.Update() retained access to original, want copy")
+			}
+		})
+	}
+}
+
+func TestDataFrame_CombineFirst(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"foo", ""}, []int{1, 2}}, Config{Index: []int{1, 2}, Col: []string{"A", "B"}})
+	type args struct {
+		other *DataFrame
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "pass",
+			args: args{MustNew([]interface{}{[]string{"bar", "baz"}, []float64{20, 30}, []string{"qux", "quux"}},
+				Config{Index: []int{2, 3}, Col: []string{"A", "B", "C"}})},
+			want: MustNew([]interface{}{[]string{"foo", "bar", "baz"}, []int{1, 2, 30}, []string{"", "qux", "quux"}},
+				Config{Index: []int{1, 2, 3}, Col: []string{"A", "B", "C"}}),
+			wantErr: false},
+		{"fail: mismatched column levels",
+			args{MustNew([]interface{}{[]string{"bar"}}, Config{Index: []int{2}, MultiCol: [][]string{{"A"}, {"a"}}})},
+			newEmptyDataFrame(), true},
+		{"fail: conversion conflict",
+			args{MustNew([]interface{}{[]string{"bar"}}, Config{Index: []int{3}, Col: []string{"B"}})},
+			newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := df.CombineFirst(tt.args.other)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.CombineFirst() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.CombineFirst() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFrame_Merge(t *testing.T) {
+	left := MustNew([]interface{}{[]string{"foo", "bar", "baz"}, []string{"1", "2", "3"}}, Config{Col: []string{"key", "A"}})
+	right := MustNew([]interface{}{[]string{"bar", "baz", "baz", "qux"}, []int{20, 30, 31, 40}, []string{"a", "b", "c", "d"}},
+		Config{Col: []string{"key", "A", "B"}})
+	right2, _ := right.SubsetColumns([]int{0, 2})
+	type args struct {
+		right  *DataFrame
+		config MergeOptions
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "inner", args: args{right, MergeOptions{On: []string{"key"}}},
+			want: MustNew([]interface{}{[]string{"bar", "baz", "baz"}, []string{"2", "3", "3"}, []int{20, 30, 31}, []string{"a", "b", "c"}},
+				Config{Col: []string{"key", "A_x", "A_y", "B"}}),
+			wantErr: false},
+		{"suffixes", args{right, MergeOptions{On: []string{"key"}, Suffixes: [2]string{"_l", "_r"}}},
+			MustNew([]interface{}{[]string{"bar", "baz", "baz"}, []string{"2", "3", "3"}, []int{20, 30, 31}, []string{"a", "b", "c"}},
+				Config{Col: []string{"key", "A_l", "A_r", "B"}}),
+			false},
+		{"left with indicator", args{right2, MergeOptions{On: []string{"key"}, How: "left", Indicator: true}},
+			MustNew([]interface{}{[]string{"foo", "bar", "baz", "baz"}, []string{"1", "2", "3", "3"}, []string{"", "a", "b", "c"},
+				[]string{"left_only", "both", "both", "both"}},
+				Config{Col: []string{"key", "A", "B", "_merge"}}),
+			false},
+		{"outer with indicator", args{right2, MergeOptions{On: []string{"key"}, How: "outer", Indicator: true}},
+			MustNew([]interface{}{[]string{"foo", "bar", "baz", "baz", "qux"}, []string{"1", "2", "3", "3", ""}, []string{"", "a", "b", "c", "d"},
+				[]string{"left_only", "both", "both", "both", "right_only"}},
+				Config{Col: []string{"key", "A", "B", "_merge"}}),
+			false},
+		{"right", args{right2, MergeOptions{On: []string{"key"}, How: "right"}},
+			MustNew([]interface{}{[]string{"bar", "baz", "baz", "qux"}, []string{"2", "3", "3", ""}, []string{"a", "b", "c", "d"}},
+				Config{Col: []string{"key", "A", "B"}}),
+			false},
+		{"one_to_many", args{right2, MergeOptions{On: []string{"key"}, Validate: "one_to_many"}},
+			MustNew([]interface{}{[]string{"bar", "baz", "baz"}, []string{"2", "3", "3"}, []string{"a", "b", "c"}},
+				Config{Col: []string{"key", "A", "B"}}),
+			false},
+		{"fail: one_to_one", args{right, MergeOptions{On: []string{"key"}, Validate: "one_to_one"}},
+			newEmptyDataFrame(), true},
+		{"fail: many_to_one", args{right, MergeOptions{On: []string{"key"}, Validate: "many_to_one"}},
+			newEmptyDataFrame(), true},
+		{"fail: invalid validate", args{right, MergeOptions{On: []string{"key"}, Validate: "corge"}},
+			newEmptyDataFrame(), true},
+		{"fail: invalid how", args{right, MergeOptions{On: []string{"key"}, How: "corge"}},
+			newEmptyDataFrame(), true},
+		{"fail: no keys", args{right, MergeOptions{}},
+			newEmptyDataFrame(), true},
+		{"fail: missing key", args{right, MergeOptions{On: []string{"corge"}}},
+			newEmptyDataFrame(), true},
+		{"fail: indicator column exists", args{MustNew([]interface{}{[]string{"bar"}, []string{"both"}}, Config{Col: []string{"key", "_merge"}}),
+			MergeOptions{On: []string{"key"}, Indicator: true}},
+			newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := left.Merge(tt.args.right, tt.args.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.Merge() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.Merge() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFrame_Merge_validateMessage(t *testing.T) {
+	left := MustNew([]interface{}{[]string{"foo", "foo", "bar"}, []int{1, 1, 2}}, Config{Col: []string{"key", "id"}})
+	_, err := left.Merge(left, MergeOptions{On: []string{"key", "id"}, Validate: "one_to_many"})
+	if err == nil || !strings.Contains(err.Error(), "[[foo 1]]") {
+		t.Errorf("DataFrame.Merge() error = %v, want error listing duplicate key [foo 1]", err)
+	}
+}
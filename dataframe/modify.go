@@ -367,7 +367,7 @@ func (r Row) hash() string {
 func (ip InPlace) DropDuplicates() {
 	var toDrop []int
 	g := ip.df.GroupByIndex()
-	for _, group := range g.Groups() {
+	for _, group := range g.ids() {
 		// only inspect groups with at least one position
 		if positions := g.groups[group].Positions; len(positions) > 0 {
 			exists := make(map[interface{}]bool)
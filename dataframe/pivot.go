@@ -2,7 +2,11 @@ package dataframe
 
 import (
 	"fmt"
-	"strings"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
 
 	"github.com/ptiger10/pd/internal/index"
 	"github.com/ptiger10/pd/internal/values"
@@ -10,160 +14,928 @@ import (
 	"github.com/ptiger10/pd/series"
 )
 
-// values:
-// make a [][]interface{} valsMatrix for rows x cols
-// # rows: unique non-stacked labels
-// # cols = unique stacked labels * number of columns
-// isolate first value of the stacked label within each non-stacked label
-// transpose to []interface and feed into interface factory to create []Values.Container
-func (df *DataFrame) stack(level int) (newIdxPositions []int, valsMatrix [][]interface{}, newColLvl []string) {
-	var unstackedIndexLevels []int
+// Unstack moves the labels in an index level to a new column level at position 0 and returns a new DataFrame.
+// The result has one row for every unique combination of labels in the other index levels, in order of first appearance,
+// and one column for every unique label in the unstacked level (in order of first appearance) and original column.
+// Combinations that do not exist in df are null. Column DataTypes are preserved.
+// If dropNull is true, rows in which every value is null are dropped.
+// Returns an error if df has only one index level or if any labels are duplicated across every index level.
+func (df *DataFrame) Unstack(level int, dropNull bool) (*DataFrame, error) {
+	if err := df.ensureIndexLevelPositions([]int{level}); err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Unstack(): %v", err)
+	}
+	if df.IndexLevels() < 2 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Unstack(): must have at least two index levels")
+	}
+	var otherLevels []int
 	for j := 0; j < df.IndexLevels(); j++ {
 		if j != level {
-			unstackedIndexLevels = append(unstackedIndexLevels, j)
-		}
-	}
-	g := df.GroupByIndex(unstackedIndexLevels...)
-
-	labelsToStack := df.Index.unique(level)
-	numRows := g.Len()
-	numCols := len(labelsToStack) * df.NumCols()
-	valsMatrix = make([][]interface{}, numRows)
-	for i := 0; i < numRows; i++ {
-		valsMatrix[i] = make([]interface{}, numCols)
-	}
-
-	// only extend the labels for the columns-to-be-stacked once
-	extendColLevel := true
-	for i, group := range g.Groups() {
-		newIdxPositions = append(newIdxPositions, g.groups[group].Positions[0])
-		rows, _ := df.SubsetRows(g.groups[group].Positions)
-		for labelOffset, label := range labelsToStack {
-			// log warnings disabled because frequently a label will not exist in an index
-			archive := options.GetLogWarnings()
-			options.SetLogWarnings(false)
-			row := rows.SelectLabels([]string{label}, level)
-			options.SetLogWarnings(archive)
-			// log warnings restored
-			for m := 0; m < df.NumCols(); m++ {
-				if len(row) > 0 {
-					valsMatrix[i][m+labelOffset*df.NumCols()] = rows.vals[m].Values.Value(row[0])
-				}
-				if extendColLevel {
-					newColLvl = append(newColLvl, label)
-				}
+			otherLevels = append(otherLevels, j)
+		}
+	}
+	labels := df.index.Levels[level].Labels
+	rowTable := make(map[string]int)
+	labelTable := make(map[string]int)
+	var firstPositions, labelPositions []int
+	// positions[r][u] is the position in df of row r and unstacked label u, or -1 if it does not exist
+	var positions [][]int
+	for i := 0; i < df.Len(); i++ {
+		var rowKey string
+		for _, j := range otherLevels {
+			rowKey += values.Key(df.index.Levels[j].Labels.Value(i))
+		}
+		r, ok := rowTable[rowKey]
+		if !ok {
+			r = len(firstPositions)
+			rowTable[rowKey] = r
+			firstPositions = append(firstPositions, i)
+			row := make([]int, len(labelPositions), len(labelPositions)+1)
+			for u := range row {
+				row[u] = -1
 			}
+			positions = append(positions, row)
 		}
-		extendColLevel = false
+		labelKey := values.Key(labels.Value(i))
+		u, ok := labelTable[labelKey]
+		if !ok {
+			u = len(labelPositions)
+			labelTable[labelKey] = u
+			labelPositions = append(labelPositions, i)
+			for k := range positions {
+				positions[k] = append(positions[k], -1)
+			}
+		}
+		if positions[r][u] != -1 {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.Unstack(): duplicate index labels: %v", df.index.Elements(i).Labels)
+		}
+		positions[r][u] = i
 	}
-	return newIdxPositions, valsMatrix, newColLvl
-}
 
-func (df *DataFrame) stackIndex(level int) *DataFrame {
-	newIdxPositions, valsMatrix, newColLevel := df.stack(level)
-	transposedVals := values.TransposeValues(valsMatrix)
-	var containers []values.Container
-	for i := 0; i < len(transposedVals); i++ {
-		container := values.MustCreateValuesFromInterface(transposedVals[i])
-		containers = append(containers, container)
+	var keep []int
+	for r := range positions {
+		if !dropNull || !df.rowsNull(positions[r]) {
+			keep = append(keep, r)
+		}
+	}
+
+	var vals []values.Container
+	var newColLevel []string
+	for u, labelPosition := range labelPositions {
+		for m := 0; m < df.NumCols(); m++ {
+			colPositions := make([]int, len(keep))
+			for k, r := range keep {
+				colPositions[k] = positions[r][u]
+			}
+			vals = append(vals, df.vals[m].Lookup(colPositions))
+			newColLevel = append(newColLevel, fmt.Sprint(labels.Value(labelPosition)))
+		}
 	}
 
-	idx := df.index.Copy()
-	idx.Subset(newIdxPositions)
-	idx.DropLevel(level)
+	rowPositions := make([]int, len(keep))
+	for k, r := range keep {
+		rowPositions[k] = firstPositions[r]
+	}
+	idxLevels := make([]index.Level, len(otherLevels))
+	for k, j := range otherLevels {
+		lvl := df.index.Levels[j]
+		idxLevels[k] = index.Level{Labels: lvl.Labels.Subset(rowPositions), DataType: lvl.DataType, Name: lvl.Name, NeedsRefresh: true}
+	}
 
 	cols := df.cols.Copy()
 	for j := 0; j < df.ColLevels(); j++ {
 		// duplicate each level enough times that it is same length as new column level
-		cols.Levels[j].Duplicate((len(newColLevel) / df.NumCols()) - 1)
+		cols.Levels[j].Duplicate(len(labelPositions) - 1)
 	}
-
 	// ducks error because input is controlled
 	cols.InsertLevel(0, newColLevel, df.index.Levels[level].Name)
+	return newFromComponents(vals, index.New(idxLevels...), cols, df.name), nil
+}
 
-	ret := newFromComponents(containers, idx, cols, df.Name())
-	if df.dataType() != options.Interface {
-		ret.InPlace.Convert(df.dataType().String())
+// Stack moves the labels in a column level to a new index level after the existing levels and returns a new DataFrame.
+// The result has one row for every original row and unique label in the stacked level (in order of first appearance),
+// and one column for every unique combination of labels in the other column levels, in order of first appearance.
+// Combinations that do not exist in df are null. Each column has the common DataType of the columns stacked into it.
+// If dropNull is true, rows in which every value is null are dropped.
+// Returns an error if df has only one column level or if any columns are duplicated across every column level.
+func (df *DataFrame) Stack(level int, dropNull bool) (*DataFrame, error) {
+	if err := df.ensureColumnLevelPositions([]int{level}); err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Stack(): %v", err)
+	}
+	if df.ColLevels() < 2 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Stack(): must have at least two column levels")
 	}
-	return ret
+	var otherLevels []int
+	for j := 0; j < df.ColLevels(); j++ {
+		if j != level {
+			otherLevels = append(otherLevels, j)
+		}
+	}
+	labels := df.cols.Levels[level].Labels
+	colTable := make(map[string]int)
+	labelTable := make(map[string]int)
+	var firstPositions []int
+	var stackedLabels []string
+	// positions[c][u] is the position in df of new column c and stacked label u, or -1 if it does not exist
+	var positions [][]int
+	for m := 0; m < df.NumCols(); m++ {
+		var colKey string
+		for _, j := range otherLevels {
+			colKey += values.Key(df.cols.Levels[j].Labels[m])
+		}
+		c, ok := colTable[colKey]
+		if !ok {
+			c = len(firstPositions)
+			colTable[colKey] = c
+			firstPositions = append(firstPositions, m)
+			col := make([]int, len(stackedLabels), len(stackedLabels)+1)
+			for u := range col {
+				col[u] = -1
+			}
+			positions = append(positions, col)
+		}
+		u, ok := labelTable[labels[m]]
+		if !ok {
+			u = len(stackedLabels)
+			labelTable[labels[m]] = u
+			stackedLabels = append(stackedLabels, labels[m])
+			for k := range positions {
+				positions[k] = append(positions[k], -1)
+			}
+		}
+		if positions[c][u] != -1 {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.Stack(): duplicate columns: %v", df.cols.MultiName(m))
+		}
+		positions[c][u] = m
+	}
+
+	// rows
+	var rowPositions, labelPositions []int
+	for i := 0; i < df.Len(); i++ {
+		for u := range stackedLabels {
+			if dropNull {
+				cols := make([]int, len(positions))
+				for c := range positions {
+					cols[c] = positions[c][u]
+				}
+				if df.cellsNull(i, cols) {
+					continue
+				}
+			}
+			rowPositions = append(rowPositions, i)
+			labelPositions = append(labelPositions, u)
+		}
+	}
+
+	// values
+	vals := make([]values.Container, len(positions))
+	for c := range positions {
+		var dataTypes []options.DataType
+		for _, m := range positions[c] {
+			if m != -1 {
+				dataTypes = append(dataTypes, df.vals[m].DataType)
+			}
+		}
+		dataType := values.CommonDataType(dataTypes...)
+		converted := make([]values.Values, len(positions[c]))
+		for u, m := range positions[c] {
+			if m != -1 {
+				// ducks error because dataType is the DataType of an existing column
+				converted[u], _ = values.Convert(df.vals[m].Values, dataType)
+			}
+		}
+		vals[c] = values.MakeNullContainer(len(rowPositions), dataType)
+		for k, i := range rowPositions {
+			src := converted[labelPositions[k]]
+			if src != nil && !src.Null(i) {
+				vals[c].Values.Set(k, src.Value(i))
+			}
+		}
+	}
+
+	// index
+	var idxLevels []index.Level
+	for _, lvl := range df.index.Levels {
+		idxLevels = append(idxLevels,
+			index.Level{Labels: lvl.Labels.Subset(rowPositions), DataType: lvl.DataType, Name: lvl.Name, NeedsRefresh: true})
+	}
+	newIdxLabels := make([]string, len(labelPositions))
+	for k, u := range labelPositions {
+		newIdxLabels[k] = stackedLabels[u]
+	}
+	newIdxLevel := index.MustNewLevel(newIdxLabels, df.cols.Levels[level].Name)
+	if dataType := df.cols.Levels[level].DataType; dataType != options.String && dataType != options.None {
+		// ducks error because column labels are converted to their original DataType
+		newIdxLevel.Labels, _ = values.Convert(newIdxLevel.Labels, dataType)
+		newIdxLevel.DataType = dataType
+	}
+	idxLevels = append(idxLevels, newIdxLevel)
+
+	// columns
+	colLevels := make([]index.ColLevel, len(otherLevels))
+	for k, j := range otherLevels {
+		colLabels := make([]string, len(firstPositions))
+		for c, m := range firstPositions {
+			colLabels[c] = df.cols.Levels[j].Labels[m]
+		}
+		colLevels[k] = index.NewColLevel(colLabels, df.cols.Levels[j].Name)
+		colLevels[k].DataType = df.cols.Levels[j].DataType
+	}
+	return newFromComponents(vals, index.New(idxLevels...), index.NewColumns(colLevels...), df.name), nil
 }
 
-// Pivot transforms data into the desired form and calls aggFunc on the reshaped data.
-func (df *DataFrame) Pivot(index int, values int, columns int, aggFunc string) (*DataFrame, error) {
+// rowsNull returns true if every value in the specified rows is null. Positions of -1 are ignored.
+func (df *DataFrame) rowsNull(rows []int) bool {
+	for _, row := range rows {
+		if row != -1 && !df.cellsNull(row, values.MakeIntRange(0, df.NumCols())) {
+			return false
+		}
+	}
+	return true
+}
+
+// cellsNull returns true if every value in the specified columns of a row is null. Positions of -1 are ignored.
+func (df *DataFrame) cellsNull(row int, cols []int) bool {
+	for _, m := range cols {
+		if m != -1 && !df.vals[m].Values.Null(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// Pivot transforms data into the desired form and calls agg on the reshaped data.
+// agg may be any series.Agg, including a built-in aggregation such as series.AggSum, a custom aggregation from series.NewAgg,
+// or one registered with series.RegisterAgg (see series.LookupAgg).
+func (df *DataFrame) Pivot(index int, values int, columns int, agg series.Agg) (*DataFrame, error) {
+	if err := df.ensureColumnPositions([]int{index, values, columns}); err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("df.Pivot(): %v", err)
+	}
 	df = df.Copy()
 	df.InPlace.SubsetColumns([]int{index, columns, values})
-	g := df.GroupBy(index, columns)
-
-	switch aggFunc {
-	case "sum":
-		df = g.Sum()
-	case "mean":
-		df = g.Mean()
-	case "median":
-		df = g.Median()
-	case "min":
-		df = g.Min()
-	case "max":
-		df = g.Max()
-	case "std":
-		df = g.Std()
-	default:
-		return newEmptyDataFrame(), fmt.Errorf("df.Pivot(): aggFunc (%v) does not exist", aggFunc)
+	df = df.GroupBy(index, columns).Reduce(agg)
+	// the pivoted index and column levels are unnamed
+	for j := range df.index.Levels {
+		df.index.Levels[j].Name = ""
+	}
+	df, err := df.Unstack(1, false)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("df.Pivot(): %v", err)
 	}
-	df = df.stackIndex(1)
 	df.Columns.DropLevel(1)
 	return df, nil
 }
 
-// Transpose transforms all rows to columns.
-func (df *DataFrame) Transpose() *DataFrame {
-	ret := newEmptyDataFrame()
+// PivotTable aggregates the Values columns of df by the unique values in the Index and Columns columns and returns a new DataFrame.
+// Rows and columns appear in order of first appearance.
+// The result has one index level for each Index column and, from outermost to innermost,
+// one column level for the value column, one for the aggregation name and one for each Columns column.
+// Each result column has the common DataType of its aggregated values. Aggregations are computed concurrently if options.GetAsync() is true.
+func (df *DataFrame) PivotTable(config PivotOptions) (*DataFrame, error) {
+	if len(config.Index) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): must supply at least one Index column")
+	}
+	idxPositions, err := df.colPositions(config.Index)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): Index: %v", err)
+	}
+	colPositions, err := df.colPositions(config.Columns)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): Columns: %v", err)
+	}
+	isGroup := make(map[int]bool)
+	for _, m := range idxPositions {
+		isGroup[m] = true
+	}
+	for _, m := range colPositions {
+		isGroup[m] = true
+	}
+	valuePositions, valueLabels := []int{}, config.Values
+	if len(config.Values) > 0 {
+		valuePositions, err = df.colPositions(config.Values)
+		if err != nil {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): Values: %v", err)
+		}
+		for k, m := range valuePositions {
+			if isGroup[m] {
+				return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): Values: %q is also an Index or Columns column", config.Values[k])
+			}
+		}
+	} else {
+		for m := 0; m < df.NumCols(); m++ {
+			if !isGroup[m] {
+				valuePositions = append(valuePositions, m)
+				valueLabels = append(valueLabels, df.cols.Levels[0].Labels[m])
+			}
+		}
+	}
+	if len(valuePositions) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): no Values columns to aggregate")
+	}
+	aggs := make([][]series.Agg, len(valueLabels))
+	for k, label := range valueLabels {
+		aggs[k] = []series.Agg{series.AggMean}
+		if aggFuncs, ok := config.AggFuncs[label]; ok {
+			if len(aggFuncs) == 0 {
+				return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): AggFuncs: no aggregations for %q", label)
+			}
+			aggs[k] = aggFuncs
+		}
+	}
+	for label := range config.AggFuncs {
+		var ok bool
+		for _, valueLabel := range valueLabels {
+			ok = ok || label == valueLabel
+		}
+		if !ok {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.PivotTable(): AggFuncs: %q is not a Values column", label)
+		}
+	}
+
+	// groups
+	rowTable, rowOrder := df.keyTable(idxPositions)
+	colTable, colOrder := df.keyTable(colPositions)
+	cells := make(map[string][]int)
+	for i := 0; i < df.Len(); i++ {
+		key := df.rowKey(i, idxPositions) + df.rowKey(i, colPositions)
+		cells[key] = append(cells[key], i)
+	}
+	numRows, numCols := len(rowOrder), len(colOrder)
+	if config.Margins {
+		numRows++
+		if len(colPositions) > 0 {
+			numCols++
+		}
+	}
+	// positions returns the rows in df that are aggregated into result row r and column key c
+	positions := func(r, c int) []int {
+		switch {
+		case r == len(rowOrder) && c == len(colOrder):
+			return values.MakeIntRange(0, df.Len())
+		case r == len(rowOrder):
+			return colTable[colOrder[c]]
+		case c == len(colOrder):
+			return rowTable[rowOrder[r]]
+		}
+		return cells[rowOrder[r]+colOrder[c]]
+	}
+
+	// values
+	type task struct {
+		value, agg, colKey int
+	}
+	var tasks []task
+	for k := range valuePositions {
+		for a := range aggs[k] {
+			for c := 0; c < numCols; c++ {
+				tasks = append(tasks, task{k, a, c})
+			}
+		}
+	}
+	vals := make([]values.Container, len(tasks))
+	calc := func(t int) {
+		k, a, c := tasks[t].value, tasks[t].agg, tasks[t].colKey
+		results := make([]interface{}, numRows)
+		for r := range results {
+			if rows := positions(r, c); len(rows) > 0 {
+				results[r] = aggs[k][a].Eval(df.subsetSeries(valuePositions[k], rows))
+			}
+			if results[r] == nil {
+				results[r] = config.FillValue
+			}
+		}
+		vals[t] = values.ScalarSliceFactory(results)
+	}
+	if !options.GetAsync() {
+		for t := range tasks {
+			calc(t)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for t := range tasks {
+			wg.Add(1)
+			go func(t int) {
+				calc(t)
+				wg.Done()
+			}(t)
+		}
+		wg.Wait()
+	}
+
+	// index
+	firstRows := make([]int, len(rowOrder))
+	for r, key := range rowOrder {
+		firstRows[r] = rowTable[key][0]
+	}
+	idxLevels := make([]index.Level, len(idxPositions))
+	for k, m := range idxPositions {
+		labels, dataType := df.vals[m].Values.Subset(firstRows), df.vals[m].DataType
+		if config.Margins {
+			label := ""
+			if k == 0 {
+				label = "All"
+			}
+			if dataType != options.String {
+				labels, dataType = labels.ToInterface(), options.Interface
+			}
+			labels.Append(values.MustCreateValuesFromInterface(label).Values)
+		}
+		idxLevels[k] = index.Level{Labels: labels, DataType: dataType, Name: config.Index[k], NeedsRefresh: true}
+	}
+
+	// columns
+	colLabels := make([][]string, 2+len(colPositions))
+	for _, t := range tasks {
+		colLabels[0] = append(colLabels[0], valueLabels[t.value])
+		colLabels[1] = append(colLabels[1], aggs[t.value][t.agg].Name())
+		for j, m := range colPositions {
+			label := ""
+			if t.colKey < len(colOrder) {
+				label = fmt.Sprint(df.vals[m].Values.Value(colTable[colOrder[t.colKey]][0]))
+			} else if j == 0 {
+				label = "All"
+			}
+			colLabels[2+j] = append(colLabels[2+j], label)
+		}
+	}
+	colLevels := make([]index.ColLevel, len(colLabels))
+	for j := range colLabels {
+		var name string
+		if j >= 2 {
+			name = config.Columns[j-2]
+		}
+		colLevels[j] = index.NewColLevel(colLabels[j], name)
+	}
+	return newFromComponents(vals, index.New(idxLevels...), index.NewColumns(colLevels...), df.name), nil
+}
+
+// Crosstab returns a frequency table of the labels in rows and cols, or an aggregation of config.Values by those labels,
+// with one row per unique label in rows and one column per unique label in cols, in order of first appearance.
+// rows, cols and config.Values are aligned on their index labels, and the index level and column level are named after rows and cols.
+func Crosstab(rows, cols *series.Series, config ...CrosstabOptions) (*DataFrame, error) {
+	var tmp CrosstabOptions
+	if len(config) > 1 {
+		return newEmptyDataFrame(), fmt.Errorf("dataframe.Crosstab(): can supply at most one CrosstabOptions (%d > 1)", len(config))
+	} else if len(config) == 1 {
+		tmp = config[0]
+	}
+	if (tmp.Values == nil) != (tmp.AggFunc.Name() == "") {
+		return newEmptyDataFrame(), fmt.Errorf("dataframe.Crosstab(): Values and AggFunc must be supplied together")
+	}
+	if tmp.Normalize != "" && tmp.Normalize != "all" && tmp.Normalize != "index" && tmp.Normalize != "columns" {
+		return newEmptyDataFrame(), fmt.Errorf("dataframe.Crosstab(): Normalize must be all, index, columns, or empty, not %q", tmp.Normalize)
+	}
+
+	// alignment
+	rowVals, rowIdx := rows.ToInternalComponents()
+	colVals, colIdx := cols.ToInternalComponents()
+	idx, rowPositions, colPositions, err := rowIdx.Align(colIdx)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("dataframe.Crosstab(): cols: %v", err)
+	}
+	agg, fillValue := series.AggCount, interface{}(0)
+	valueVals := values.MustCreateValuesFromInterface(make([]int, idx.Len()))
+	if tmp.Values != nil {
+		vals, valueIdx := tmp.Values.ToInternalComponents()
+		_, _, valuePositions, err := idx.Align(valueIdx)
+		if err != nil {
+			return newEmptyDataFrame(), fmt.Errorf("dataframe.Crosstab(): Values: %v", err)
+		}
+		// rows only in Values have no row or column label, so they are excluded
+		valueVals = vals.Lookup(valuePositions[:idx.Len()])
+		agg, fillValue = tmp.AggFunc, nil
+	}
+	df := newFromComponents(
+		[]values.Container{rowVals.Lookup(rowPositions), colVals.Lookup(colPositions), valueVals},
+		index.NewDefault(idx.Len()),
+		index.NewColumns(index.NewColLevel([]string{"rows", "cols", "values"}, "")), "")
+	if tmp.DropNA {
+		var keep []int
+		for i := 0; i < df.Len(); i++ {
+			if !df.vals[0].Values.Null(i) && !df.vals[1].Values.Null(i) {
+				keep = append(keep, i)
+			}
+		}
+		df = df.subsetRows(keep)
+	}
+
+	ret, err := df.PivotTable(PivotOptions{Index: []string{"rows"}, Columns: []string{"cols"}, Values: []string{"values"},
+		AggFuncs: map[string][]series.Agg{"values": {agg}}, FillValue: fillValue, Margins: tmp.Margins})
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("dataframe.Crosstab(): %v", err)
+	}
+	ret.index.Levels[0].Name = rows.Name()
+	// ducks error because the pivot table has three column levels
+	ret.cols.SubsetLevels([]int{2})
+	ret.cols.Levels[0].Name = cols.Name()
+	ret.cols.Refresh()
+	if tmp.Normalize != "" {
+		ret.normalize(tmp.Normalize, tmp.Margins)
+	}
+	return ret, nil
+}
+
+// normalize converts every value in df to float64 and divides it by the total of all values, or of its row ("index") or column ("columns").
+// If margins is true, the last row and column hold margins, which are excluded from the totals of other rows and columns.
+func (df *DataFrame) normalize(by string, margins bool) {
+	numRows, numCols := df.Len(), df.NumCols()
+	if margins {
+		numRows--
+		numCols--
+	}
+	floats := make([][]float64, df.NumCols())
+	rowTotals := make([]float64, df.Len())
+	colTotals := make([]float64, df.NumCols())
+	var total float64
+	for m := range floats {
+		floats[m] = df.vals[m].Values.ToFloat64().Vals().([]float64)
+		for i, v := range floats[m] {
+			if df.vals[m].Values.Null(i) {
+				floats[m][i] = math.NaN()
+				continue
+			}
+			if m < numCols {
+				rowTotals[i] += v
+			}
+			if i < numRows {
+				colTotals[m] += v
+			}
+			if i < numRows && m < numCols {
+				total += v
+			}
+		}
+	}
+	for m := range floats {
+		for i := range floats[m] {
+			switch by {
+			case "all":
+				floats[m][i] /= total
+			case "index":
+				floats[m][i] /= rowTotals[i]
+			case "columns":
+				floats[m][i] /= colTotals[m]
+			}
+		}
+		df.vals[m] = values.MustCreateValuesFromInterface(floats[m])
+	}
+}
+
+// Melt unpivots the columns in valueVars into rows and returns a new DataFrame with a default index.
+// The result has the columns in idVars, repeated once for each column in valueVars,
+// followed by a variable column with the label of the melted column and a value column with its value.
+// idVars and valueVars are labels in column level 0. If valueVars is empty, every column not in idVars is melted.
+//
+// varName and valueName (default: "value") name the new columns. If varName is empty,
+// the variable column is named after the column level, or "variable" if the level is unnamed.
+// If the columns have multiple levels, each level becomes a separate variable column,
+// named after the column level or, if it is unnamed, after varName and the level position (e.g., "variable_1").
+// The value column has the common DataType of the melted columns (float64 if they mix int64 and float64, interface if otherwise mixed).
+func (df *DataFrame) Melt(idVars, valueVars []string, varName, valueName string) (*DataFrame, error) {
+	varPrefix := varName
+	if varPrefix == "" {
+		varPrefix = "variable"
+	}
+	if valueName == "" {
+		valueName = "value"
+	}
+	idPositions, err := df.colPositions(idVars)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Melt(): idVars: %v", err)
+	}
+	var valuePositions []int
+	if len(valueVars) > 0 {
+		valuePositions, err = df.colPositions(valueVars)
+		if err != nil {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.Melt(): valueVars: %v", err)
+		}
+	} else {
+		isID := make(map[int]bool)
+		for _, m := range idPositions {
+			isID[m] = true
+		}
+		for m := 0; m < df.NumCols(); m++ {
+			if !isID[m] {
+				valuePositions = append(valuePositions, m)
+			}
+		}
+	}
+	if len(valuePositions) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Melt(): no columns to melt")
+	}
+
+	n := df.Len() * len(valuePositions)
+	var vals []values.Container
+	var labels []string
+
+	// id columns
+	repeated := make([]int, 0, n)
+	for range valuePositions {
+		repeated = append(repeated, values.MakeIntRange(0, df.Len())...)
+	}
+	for k, m := range idPositions {
+		vals = append(vals, values.Container{Values: df.vals[m].Values.Subset(repeated), DataType: df.vals[m].DataType})
+		labels = append(labels, idVars[k])
+	}
+
+	// variable columns
+	for j := 0; j < df.ColLevels(); j++ {
+		varLabels := make([]string, 0, n)
+		for _, m := range valuePositions {
+			for i := 0; i < df.Len(); i++ {
+				varLabels = append(varLabels, df.cols.Levels[j].Labels[m])
+			}
+		}
+		vals = append(vals, values.MustCreateValuesFromInterface(varLabels))
+		name := df.cols.Levels[j].Name
+		if df.ColLevels() == 1 && (varName != "" || name == "") {
+			name = varPrefix
+		} else if name == "" {
+			name = fmt.Sprintf("%v_%d", varPrefix, j)
+		}
+		labels = append(labels, name)
+	}
+
+	// value column
+	dataTypes := make([]options.DataType, len(valuePositions))
+	for k, m := range valuePositions {
+		dataTypes[k] = df.vals[m].DataType
+	}
+	dataType := values.CommonDataType(dataTypes...)
+	var melted values.Values
+	for _, m := range valuePositions {
+		// ducks error because dataType is the DataType of an existing column
+		converted, _ := values.Convert(df.vals[m].Values, dataType)
+		if melted == nil {
+			melted = converted
+			continue
+		}
+		melted.Append(converted)
+	}
+	vals = append(vals, values.Container{Values: melted, DataType: dataType})
+	labels = append(labels, valueName)
+
+	if _, err := nameTable(labels); err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Melt(): %v", err)
+	}
+	cols := index.NewColumns(index.NewColLevel(labels, ""))
+	return newFromComponents(vals, index.NewDefault(n), cols, df.name), nil
+}
+
+// WideToLong reshapes the columns named stub + sep + suffix into rows and returns a new DataFrame with a default index,
+// with one row for every original row and suffix, ordered by row and then by suffix in order of first appearance.
+// suffix is a regular expression that must match the entire suffix (default: `\d+`).
+// The result contains the id columns i, the other columns that do not match a stub, a column named j of suffixes,
+// then one column per stub with the common DataType of its columns.
+// The j column is int64 if every suffix is an integer, float64 if every suffix is a number, and string otherwise.
+// Returns an error if the id columns do not uniquely identify each row, if a stub matches no columns,
+// or if the stubs do not have the same suffixes.
+func (df *DataFrame) WideToLong(stubnames []string, i []string, j string, sep string, suffix string) (*DataFrame, error) {
+	if len(stubnames) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): must supply at least one stub name")
+	}
+	if len(i) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): must supply at least one id column")
+	}
+	if j == "" {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): must supply a name for the suffix column")
+	}
+	if df.ColLevels() != 1 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): must have a single column level")
+	}
+	if suffix == "" {
+		suffix = `\d+`
+	}
+	idPositions, err := df.colPositions(i)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): i: %v", err)
+	}
+	table, order := df.keyTable(idPositions)
+	if duplicates := df.duplicateKeys(idPositions, table, order); len(duplicates) > 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): id columns do not uniquely identify each row: %v", duplicates)
+	}
+
+	// stubs[k][s] is the position of the column with stub k and suffix s
+	stubs := make([][]int, len(stubnames))
+	var suffixes []string
+	isStub := make(map[int]bool)
+	for k, stub := range stubnames {
+		re, err := regexp.Compile("^" + regexp.QuoteMeta(stub+sep) + "(" + suffix + ")$")
+		if err != nil {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): suffix: %v", err)
+		}
+		matches := make(map[string]int)
+		var stubSuffixes []string
+		for m, label := range df.cols.Levels[0].Labels {
+			if match := re.FindStringSubmatch(label); match != nil {
+				matches[match[1]] = m
+				stubSuffixes = append(stubSuffixes, match[1])
+				isStub[m] = true
+			}
+		}
+		if len(stubSuffixes) == 0 {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): no columns match stub %q", stub)
+		}
+		if k == 0 {
+			suffixes = stubSuffixes
+		}
+		if len(stubSuffixes) != len(suffixes) {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): stub %q has suffixes %v, want %v", stub, stubSuffixes, suffixes)
+		}
+		stubs[k] = make([]int, len(suffixes))
+		for s, sfx := range suffixes {
+			m, ok := matches[sfx]
+			if !ok {
+				return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): stub %q has suffixes %v, want %v", stub, stubSuffixes, suffixes)
+			}
+			stubs[k][s] = m
+		}
+	}
+
+	var vals []values.Container
+	var labels []string
+
+	// id and other columns
+	n := df.Len() * len(suffixes)
+	repeated := make([]int, 0, n)
+	for row := 0; row < df.Len(); row++ {
+		for range suffixes {
+			repeated = append(repeated, row)
+		}
+	}
+	isID := make(map[int]bool)
+	for _, m := range idPositions {
+		isID[m] = true
+	}
+	others := append([]int{}, idPositions...)
 	for m := 0; m < df.NumCols(); m++ {
-		row := transposeSeries(df.hydrateSeries(m))
-		ret.InPlace.appendDataFrameRow(row)
+		if !isID[m] && !isStub[m] {
+			others = append(others, m)
+		}
+	}
+	for _, m := range others {
+		vals = append(vals, values.Container{Values: df.vals[m].Values.Subset(repeated), DataType: df.vals[m].DataType})
+		labels = append(labels, df.cols.Levels[0].Labels[m])
+	}
+
+	// suffix column
+	suffixLabels := make([]string, 0, n)
+	for row := 0; row < df.Len(); row++ {
+		suffixLabels = append(suffixLabels, suffixes...)
+	}
+	vals = append(vals, parseSuffixes(suffixLabels))
+	labels = append(labels, j)
+
+	// stub columns
+	for k, stub := range stubnames {
+		dataTypes := make([]options.DataType, len(suffixes))
+		for s, m := range stubs[k] {
+			dataTypes[s] = df.vals[m].DataType
+		}
+		dataType := values.CommonDataType(dataTypes...)
+		converted := make([]values.Values, len(suffixes))
+		for s, m := range stubs[k] {
+			// ducks error because dataType is the DataType of an existing column
+			converted[s], _ = values.Convert(df.vals[m].Values, dataType)
+		}
+		container := values.MakeNullContainer(n, dataType)
+		for row := 0; row < df.Len(); row++ {
+			for s := range suffixes {
+				if !converted[s].Null(row) {
+					container.Values.Set(row*len(suffixes)+s, converted[s].Value(row))
+				}
+			}
+		}
+		vals = append(vals, container)
+		labels = append(labels, stub)
+	}
+
+	if _, err := nameTable(labels); err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.WideToLong(): %v", err)
+	}
+	cols := index.NewColumns(index.NewColLevel(labels, df.cols.Levels[0].Name))
+	return newFromComponents(vals, index.NewDefault(n), cols, df.name), nil
+}
+
+// parseSuffixes returns an int64 Container if every suffix is an integer, a float64 Container if every suffix is a number,
+// and a string Container otherwise.
+func parseSuffixes(suffixes []string) values.Container {
+	ints := make([]int64, len(suffixes))
+	floats := make([]float64, len(suffixes))
+	isInt, isFloat := true, true
+	for k, sfx := range suffixes {
+		if isInt {
+			v, err := strconv.ParseInt(sfx, 10, 64)
+			isInt = err == nil
+			ints[k] = v
+		}
+		v, err := strconv.ParseFloat(sfx, 64)
+		if err != nil {
+			isFloat = false
+			break
+		}
+		floats[k] = v
+	}
+	switch {
+	case isInt && isFloat:
+		return values.MustCreateValuesFromInterface(ints)
+	case isFloat:
+		return values.MustCreateValuesFromInterface(floats)
+	default:
+		return values.MustCreateValuesFromInterface(suffixes)
 	}
-	return ret
 }
 
-func transposeSeries(s *series.Series) *DataFrame {
+// Explode returns a new DataFrame with one row for every element of the list-like values (e.g., []interface{} or []string)
+// in the columns named cols, repeating the values in the other columns and the index labels of the original row.
+// Null values and empty lists become a single null, and the DataType of each exploded column is re-inferred from its non-null values.
+// If multiple columns are supplied, the lists in each row must have the same length in every column.
+func (df *DataFrame) Explode(cols ...string) (*DataFrame, error) {
+	if len(cols) == 0 {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Explode(): must supply at least one column")
+	}
+	colPositions, err := df.colPositions(cols)
+	if err != nil {
+		return newEmptyDataFrame(), fmt.Errorf("DataFrame.Explode(): %v", err)
+	}
+	if df.Len() == 0 {
+		return df.Copy(), nil
+	}
+	exploded := make(map[int]values.Container, len(colPositions))
+	var rowPositions []int
+	for k, m := range colPositions {
+		container, positions := df.vals[m].Explode()
+		if rowPositions != nil && !reflect.DeepEqual(positions, rowPositions) {
+			return newEmptyDataFrame(), fmt.Errorf("DataFrame.Explode(): column %v has different list lengths than column %v", cols[k], cols[0])
+		}
+		rowPositions = positions
+		exploded[m] = container
+	}
+
+	vals := make([]values.Container, df.NumCols())
+	for m := range df.vals {
+		if container, ok := exploded[m]; ok {
+			vals[m] = container
+			continue
+		}
+		vals[m] = values.Container{Values: df.vals[m].Values.Subset(rowPositions), DataType: df.vals[m].DataType}
+	}
+	return newFromComponents(vals, df.subsetIndex(rowPositions), df.cols.Copy(), df.name), nil
+}
+
+// Transpose transforms all rows to columns and returns a new DataFrame.
+// Each index level becomes a column level and each column level becomes an index level, with the same names.
+// If every column has the same DataType, the transposed columns keep it. Otherwise they have the common DataType of the columns
+// (float64 if they mix int64 and float64, interface if otherwise mixed).
+// Int64, float64 and bool column labels become index labels of the column level's DataType; other labels become strings.
+func (df *DataFrame) Transpose() *DataFrame {
+	if df.NumCols() == 0 {
+		return newEmptyDataFrame()
+	}
 	// Columns
-	lvls := make([]index.ColLevel, s.NumLevels())
-	cols := index.NewColumns(lvls...)
-	container, idx := s.ToInternalComponents()
-	for j := 0; j < s.NumLevels(); j++ {
-		cols.Levels[j].IsDefault = idx.Levels[j].IsDefault
-		cols.Levels[j].DataType = idx.Levels[j].DataType
-		cols.Levels[j].Name = idx.Levels[j].Name
-		for m := 0; m < s.Len(); m++ {
-			val := idx.Levels[j].Labels.Value(m)
-			// TODO: test null value
-			// if !elem.Null {
-			// 	cols.Levels[j].Labels = append(cols.Levels[j].Labels, fmt.Sprint(elem.Value))
-			// } else {
-			// 	cols.Levels[j].Labels = append(cols.Levels[j].Labels, "")
-			// }
-			cols.Levels[j].Labels = append(cols.Levels[j].Labels, fmt.Sprint(val))
-		}
-	}
-	cols.Refresh()
+	colLvls := make([]index.ColLevel, df.IndexLevels())
+	for j, lvl := range df.index.Levels {
+		labels := make([]string, df.Len())
+		for i := range labels {
+			if !lvl.Labels.Null(i) {
+				labels[i] = fmt.Sprint(lvl.Labels.Value(i))
+			}
+		}
+		colLvls[j] = index.NewColLevel(labels, lvl.Name)
+		colLvls[j].DataType = lvl.DataType
+		colLvls[j].IsDefault = lvl.IsDefault
+	}
 
 	// Index
-	names := strings.Split(s.Name(), values.GetMultiColNameSeparator())
-	idxLvls := make([]index.Level, len(names))
-	retIdx := index.New(idxLvls...)
-	for j := 0; j < len(names); j++ {
-		name := names[j]
-		// ducks error because type is known to be supported
-		retIdx.Levels[j], _ = index.NewLevel(values.InterpolateString(name), "")
+	idxLvls := make([]index.Level, df.ColLevels())
+	for j, lvl := range df.cols.Levels {
+		labels := values.MustCreateValuesFromInterface(lvl.Labels)
+		switch lvl.DataType {
+		case options.Int64, options.Float64, options.Bool:
+			// ducks error because dataType is supported
+			labels.Values, _ = values.Convert(labels.Values, lvl.DataType)
+			labels.DataType = lvl.DataType
+		}
+		idxLvls[j] = index.Level{Labels: labels.Values, DataType: labels.DataType, Name: lvl.Name, IsDefault: lvl.IsDefault, NeedsRefresh: true}
 	}
-	retIdx.NeedsRefresh = true
 
 	// Values
-	vals := make([]values.Container, s.Len())
-	for m := 0; m < s.Len(); m++ {
-		vals[m].Values = container.Values.Subset([]int{m})
-		vals[m].DataType = container.DataType
+	dataTypes := make([]options.DataType, df.NumCols())
+	for m := range df.vals {
+		dataTypes[m] = df.vals[m].DataType
 	}
-
-	return newFromComponents(vals, retIdx, cols, "")
+	dataType := values.CommonDataType(dataTypes...)
+	converted := make([]values.Values, df.NumCols())
+	for m := range df.vals {
+		// ducks error because dataType is the DataType of an existing column
+		converted[m], _ = values.Convert(df.vals[m].Values, dataType)
+	}
+	vals := make([]values.Container, df.Len())
+	for i := range vals {
+		vals[i] = values.MakeNullContainer(df.NumCols(), dataType)
+		for m := range converted {
+			if !converted[m].Null(i) {
+				vals[i].Values.Set(m, converted[m].Value(i))
+			}
+		}
+	}
+	return newFromComponents(vals, index.New(idxLvls...), index.NewColumns(colLvls...), df.name)
 }
@@ -1,7 +1,6 @@
 package dataframe
 
 import (
-	"reflect"
 	"strings"
 	"testing"
 
@@ -9,178 +8,166 @@ import (
 	"github.com/ptiger10/pd/series"
 )
 
-func TestDataFrame_Pivot_stack(t *testing.T) {
+func TestDataFrame_Unstack(t *testing.T) {
 	type args struct {
-		level int
-	}
-	type want struct {
-		newIdxPositions []int
-		vals            [][]interface{}
-		newColLevel     []string
+		level    int
+		dropNull bool
 	}
 	tests := []struct {
-		name  string
-		input *DataFrame
-		args  args
-		want  want
+		name    string
+		input   *DataFrame
+		args    args
+		want    *DataFrame
+		wantErr bool
 	}{
-		{name: "one index, one group, one column", args: args{level: 1},
+		{name: "one index, one group, one column", args: args{1, false},
 			input: MustNew([]interface{}{[]float64{1}},
-				Config{Col: []string{"A"},
-					MultiIndex: []interface{}{
-						[]string{"foo"}, []string{"qux"},
-					}}),
-			want: want{newIdxPositions: []int{0}, vals: [][]interface{}{{1.0}}, newColLevel: []string{"qux"}}},
-		{name: "one index, two groups, one column", args: args{level: 1},
-			input: MustNew([]interface{}{[]float64{1, 2}},
-				Config{Col: []string{"A"},
-					MultiIndex: []interface{}{
-						[]string{"foo", "foo"},
-						[]string{"qux", "bar"},
-					}}),
-			want: want{newIdxPositions: []int{0}, vals: [][]interface{}{{1.0, 2.0}}, newColLevel: []string{"qux", "bar"}}},
-		{name: "one index, one group, two columns", args: args{level: 1},
-			input: MustNew([]interface{}{[]float64{1}, []float64{2}},
-				Config{Col: []string{"A", "B"},
-					MultiIndex: []interface{}{
-						[]string{"foo"},
-						[]string{"qux"},
-					}}),
-			want: want{newIdxPositions: []int{0}, vals: [][]interface{}{{1.0, 2.0}},
-				newColLevel: []string{"qux", "qux"}},
-		},
-		{name: "one index, two groups, two columns", args: args{level: 1},
-			input: MustNew([]interface{}{[]float64{1, 2}, []float64{3, 4}},
-				Config{Col: []string{"A", "B"},
-					MultiIndex: []interface{}{
-						[]string{"foo", "foo"},
-						[]string{"qux", "bar"},
-					}}),
-			want: want{newIdxPositions: []int{0}, vals: [][]interface{}{{1.0, 3.0, 2.0, 4.0}},
-				newColLevel: []string{"qux", "qux", "bar", "bar"}},
-		},
-		{name: "two indexes, one group, one column", args: args{level: 1},
-			input: MustNew([]interface{}{[]float64{1, 2}},
-				Config{Col: []string{"A"},
-					MultiIndex: []interface{}{
-						[]string{"foo", "bar"},
-						[]string{"qux", "qux"},
-					}}),
-			want: want{newIdxPositions: []int{0, 1}, vals: [][]interface{}{{1.0}, {2.0}},
-				newColLevel: []string{"qux"}},
-		},
-		{name: "two indexes, two groups, one column", args: args{level: 1},
-			input: MustNew([]interface{}{[]float64{1, 2}},
-				Config{Col: []string{"A"},
-					MultiIndex: []interface{}{
-						[]string{"foo", "bar"},
-						[]string{"qux", "baz"},
-					}}),
-			want: want{newIdxPositions: []int{0, 1}, vals: [][]interface{}{{1.0, nil}, {nil, 2.0}},
-				newColLevel: []string{"qux", "baz"}},
-		},
-		{name: "two indexes, two groups, two columns", args: args{level: 1},
-			input: MustNew([]interface{}{[]float64{1, 2}, []float64{3, 4}},
-				Config{Col: []string{"A", "B"},
-					MultiIndex: []interface{}{
-						[]string{"foo", "bar"},
-						[]string{"qux", "baz"},
-					}}),
-			want: want{newIdxPositions: []int{0, 1}, vals: [][]interface{}{{1.0, 3.0, nil, nil}, {nil, nil, 2.0, 4.0}},
-				newColLevel: []string{"qux", "qux", "baz", "baz"}},
-		},
+				Config{Col: []string{"A"}, MultiIndex: []interface{}{[]string{"foo"}, []string{"qux"}}}),
+			want: MustNew([]interface{}{[]float64{1}},
+				Config{Index: []string{"foo"}, MultiCol: [][]string{{"qux"}, {"A"}}}),
+			wantErr: false},
+		{"one index, two groups, one column", MustNew([]interface{}{[]float64{1, 2}},
+			Config{Col: []string{"A"}, MultiIndex: []interface{}{[]string{"foo", "foo"}, []string{"qux", "bar"}}}),
+			args{1, false},
+			MustNew([]interface{}{[]float64{1}, []float64{2}},
+				Config{Index: []string{"foo"}, MultiCol: [][]string{{"qux", "bar"}, {"A", "A"}}}),
+			false},
+		{"one index, two groups, two columns", MustNew([]interface{}{[]float64{1, 2}, []float64{3, 4}},
+			Config{Col: []string{"A", "B"}, MultiIndex: []interface{}{[]string{"foo", "foo"}, []string{"qux", "bar"}}}),
+			args{1, false},
+			MustNew([]interface{}{[]float64{1}, []float64{3}, []float64{2}, []float64{4}},
+				Config{Index: []string{"foo"}, MultiCol: [][]string{{"qux", "qux", "bar", "bar"}, {"A", "B", "A", "B"}}}),
+			false},
+		{"two indexes, one group, one column", MustNew([]interface{}{[]float64{1, 2}},
+			Config{Col: []string{"A"}, MultiIndex: []interface{}{[]string{"foo", "bar"}, []string{"qux", "qux"}}}),
+			args{1, false},
+			MustNew([]interface{}{[]float64{1, 2}},
+				Config{Index: []string{"foo", "bar"}, MultiCol: [][]string{{"qux"}, {"A"}}}),
+			false},
+		{"two indexes, two groups, one column", MustNew([]interface{}{[]string{"a", "b"}},
+			Config{Col: []string{"A"}, MultiIndex: []interface{}{[]string{"foo", "bar"}, []string{"qux", "baz"}}}),
+			args{1, false},
+			MustNew([]interface{}{[]string{"a", ""}, []string{"", "b"}},
+				Config{Index: []string{"foo", "bar"}, MultiCol: [][]string{{"qux", "baz"}, {"A", "A"}}}),
+			false},
+		{"outer level with named index", MustNew([]interface{}{[]string{"a", "b", "c"}},
+			Config{Col: []string{"A"}, MultiIndex: []interface{}{[]string{"x", "x", "y"}, []int{1, 2, 1}},
+				MultiIndexNames: []string{"foo", "bar"}}),
+			args{0, false},
+			MustNew([]interface{}{[]string{"a", "b"}, []string{"c", ""}},
+				Config{Index: []int{1, 2}, IndexName: "bar", MultiCol: [][]string{{"x", "y"}, {"A", "A"}}, MultiColNames: []string{"foo", ""}}),
+			false},
+		{"drop null", MustNew([]interface{}{[]string{"a", ""}},
+			Config{Col: []string{"A"}, MultiIndex: []interface{}{[]string{"foo", "bar"}, []string{"qux", "baz"}}}),
+			args{1, true},
+			MustNew([]interface{}{[]string{"a"}, []string{""}},
+				Config{Index: []string{"foo"}, MultiCol: [][]string{{"qux", "baz"}, {"A", "A"}}}),
+			false},
+		{"fail: one index level", MustNew([]interface{}{[]int{1}}), args{0, false}, newEmptyDataFrame(), true},
+		{"fail: invalid level", MustNew([]interface{}{[]int{1}}, Config{MultiIndex: []interface{}{"foo", "bar"}}),
+			args{2, false}, newEmptyDataFrame(), true},
+		{"fail: duplicate labels", MustNew([]interface{}{[]int{1, 2}},
+			Config{MultiIndex: []interface{}{[]string{"foo", "foo"}, []string{"bar", "bar"}}}),
+			args{1, false}, newEmptyDataFrame(), true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			newIdxPositions, vals, newColLevel := tt.input.stack(tt.args.level)
-			if !reflect.DeepEqual(newIdxPositions, tt.want.newIdxPositions) {
-				t.Errorf("DataFrame.stackIndex() newIdxPositions = %v, want %v", newIdxPositions, tt.want.newIdxPositions)
-			}
-			if !reflect.DeepEqual(vals, tt.want.vals) {
-				t.Errorf("DataFrame.stackIndex() valsMatrix = %v, want %v", vals, tt.want.vals)
+			got, err := tt.input.Unstack(tt.args.level, tt.args.dropNull)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.Unstack() error = %v, want %v", err, tt.wantErr)
 			}
-			if !reflect.DeepEqual(newColLevel, tt.want.newColLevel) {
-				t.Errorf("DataFrame.stackIndex() newColLevel = %v, want %v", newColLevel, tt.want.newColLevel)
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.Unstack() = %v, want %v", got, tt.want)
 			}
-
 		})
 	}
 }
 
-// TODO: Support different types
-// func TestDataFrame_Pivot_stackColumn(t *testing.T) {
-// 	type args struct {
-// 		col int
-// 	}
-// 	tests := []struct {
-// 		name  string
-// 		input *DataFrame
-// 		args  args
-// 		want  *DataFrame
-// 	}{
-// 		{name: "one group",
-// 			input: MustNew([]interface{}{[]int64{1, 1, 1}, []string{"foo", "bar", "baz"}},
-// 				Config{Col: []string{"A", "B"}}),
-// 			args: args{col: 0},
-// 			want: MustNew([]interface{}{[]string{"foo", "bar", "baz"}},
-// 				Config{Col: []string{"1"}, ColName: "A"})},
-// 		{name: "two groups",
-// 			input: MustNew([]interface{}{[]int64{1, 1, 2}, []string{"foo", "bar", "baz"}},
-// 				Config{Col: []string{"A", "B"}}),
-// 			args: args{col: 0},
-// 			want: MustNew([]interface{}{[]string{"foo", "bar", ""}, []string{"", "", "baz"}},
-// 				Config{Col: []string{"1", "2"}, ColName: "A"})},
-// 		// {name: "different types",
-// 		// 	input: MustNew([]interface{}{[]int64{1, 1, 2}, []string{"foo", "bar", "baz"}},
-// 		// 		Config{Col: []string{"A", "B"}}),
-// 		// 	args: args{col: 0},
-// 		// 	want: MustNew([]interface{}{[]string{"foo", "bar", ""}, []string{"", "", "baz"}},
-// 		// 		Config{Col: []string{"1", "2"}, ColName: "A"})},
-// 	}
-// 	for _, tt := range tests {
-// 		t.Run(tt.name, func(t *testing.T) {
-// 			got := tt.input.stackCol(tt.args.col)
-// 			if !Equal(got, tt.want) {
-// 				t.Errorf("DataFrame.stackColumn() = %v, want %v", got, tt.want)
-// 			}
-// 		})
-// 	}
-// }
+func TestDataFrame_Unstack_dataTypes(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2}, []bool{true, false}},
+		Config{MultiIndex: []interface{}{[]string{"foo", "bar"}, []string{"qux", "baz"}}})
+	got, err := df.Unstack(1, false)
+	if err != nil {
+		t.Errorf("DataFrame.Unstack() error: %v", err)
+	}
+	want := []options.DataType{options.Int64, options.Bool, options.Int64, options.Bool}
+	for m, dataType := range want {
+		if got.vals[m].DataType != dataType {
+			t.Errorf("DataFrame.Unstack() column %d DataType = %v, want %v", m, got.vals[m].DataType, dataType)
+		}
+	}
+	if !got.vals[0].Values.Null(1) {
+		t.Errorf("DataFrame.Unstack() returned non-null value for missing combination")
+	}
+}
 
-// TODO: Multiple index levels
-func TestDataFrame_Pivot_stackIndex(t *testing.T) {
+func TestDataFrame_Stack(t *testing.T) {
+	multi := MustNew([]interface{}{[]string{"1"}, []string{"2"}, []string{"3"}},
+		Config{MultiCol: [][]string{{"x", "x", "y"}, {"a", "b", "a"}}, MultiColNames: []string{"", "foo"}})
 	type args struct {
-		col int
+		level    int
+		dropNull bool
 	}
 	tests := []struct {
-		name  string
-		input *DataFrame
-		args  args
-		want  *DataFrame
+		name    string
+		input   *DataFrame
+		args    args
+		want    *DataFrame
+		wantErr bool
 	}{
-		{name: "one group, one index level, one column",
-			input: MustNew([]interface{}{[]string{"foo"}},
-				Config{MultiIndex: []interface{}{[]string{"bar"}, []string{"baz"}}, Col: []string{"A"}}),
-			args: args{col: 1},
-			want: MustNew([]interface{}{[]string{"foo"}},
-				Config{Index: []string{"bar"}, MultiCol: [][]string{{"baz"}, {"A"}}})},
-		{name: "two groups, one index level, one column",
-			input: MustNew([]interface{}{[]string{"foo", "bar"}},
-				Config{MultiIndex: []interface{}{[]string{"baz", "baz"}, []string{"qux", "quuz"}}, Col: []string{"A"}}),
-			args: args{col: 1},
-			want: MustNew([]interface{}{[]string{"foo"}, []string{"bar"}},
-				Config{Index: []string{"baz"}, MultiCol: [][]string{{"qux", "quuz"}, {"A", "A"}}})},
+		{name: "inner level", input: multi, args: args{1, false},
+			want: MustNew([]interface{}{[]string{"1", "2"}, []string{"3", ""}},
+				Config{MultiIndex: []interface{}{[]int{0, 0}, []string{"a", "b"}}, MultiIndexNames: []string{"", "foo"},
+					Col: []string{"x", "y"}}),
+			wantErr: false},
+		{"outer level with common DataType", MustNew([]interface{}{[]int{1}, []float64{2.5}},
+			Config{MultiCol: [][]string{{"x", "y"}, {"a", "a"}}}),
+			args{0, false},
+			MustNew([]interface{}{[]float64{1, 2.5}},
+				Config{MultiIndex: []interface{}{[]int{0, 0}, []string{"x", "y"}}, Col: []string{"a"}}),
+			false},
+		{"drop null", MustNew([]interface{}{[]string{"1"}, []string{""}, []string{"3"}},
+			Config{MultiCol: [][]string{{"x", "x", "y"}, {"a", "b", "a"}}}),
+			args{1, true},
+			MustNew([]interface{}{[]string{"1"}, []string{"3"}},
+				Config{MultiIndex: []interface{}{[]int{0}, []string{"a"}}, Col: []string{"x", "y"}}),
+			false},
+		{"fail: one column level", MustNew([]interface{}{[]int{1}}), args{0, false}, newEmptyDataFrame(), true},
+		{"fail: invalid level", multi, args{2, false}, newEmptyDataFrame(), true},
+		{"fail: duplicate columns", MustNew([]interface{}{[]int{1}, []int{2}},
+			Config{MultiCol: [][]string{{"x", "x"}, {"a", "a"}}}),
+			args{1, false}, newEmptyDataFrame(), true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.input.stackIndex(tt.args.col)
+			got, err := tt.input.Stack(tt.args.level, tt.args.dropNull)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.Stack() error = %v, want %v", err, tt.wantErr)
+			}
 			if !Equal(got, tt.want) {
-				t.Errorf("DataFrame.stackIndex() = %v, want %v", got, tt.want)
+				t.Errorf("DataFrame.Stack() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestDataFrame_Stack_roundTrip(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2, 3, 4}},
+		Config{Col: []string{"A"}, MultiIndex: []interface{}{[]string{"foo", "foo", "bar", "bar"}, []string{"x", "y", "x", "y"}},
+			MultiIndexNames: []string{"baz", "qux"}})
+	unstacked, err := df.Unstack(1, false)
+	if err != nil {
+		t.Errorf("DataFrame.Unstack() error: %v", err)
+	}
+	got, err := unstacked.Stack(0, false)
+	if err != nil {
+		t.Errorf("DataFrame.Stack() error: %v", err)
+	}
+	if !Equal(got, df) {
+		t.Errorf("DataFrame.Stack() after Unstack() = %v, want %v", got, df)
+	}
+}
+
 func TestTranspose(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -192,12 +179,21 @@ func TestTranspose(t *testing.T) {
 				Config{Col: []string{"A", "B"}, Index: []string{"1", "2", "3"}}),
 			want: MustNew([]interface{}{[]string{"qux", "foo"}, []string{"quux", "bar"}, []string{"quuz", "baz"}},
 				Config{Col: []string{"1", "2", "3"}, Index: []string{"A", "B"}})},
-		// TODO: support transposing index name onto column (impossible to add to transposeSeries)
 		{name: "pass with column name",
 			input: MustNew([]interface{}{[]string{"qux"}, []string{"foo"}},
-				Config{Col: []string{"A", "B"}, Index: []string{"1"}, IndexName: "corge"}),
+				Config{Col: []string{"A", "B"}, ColName: "grault", Index: []string{"1"}, IndexName: "corge"}),
 			want: MustNew([]interface{}{[]string{"qux", "foo"}},
-				Config{Col: []string{"1"}, ColName: "corge", Index: []string{"A", "B"}})},
+				Config{Col: []string{"1"}, ColName: "corge", Index: []string{"A", "B"}, IndexName: "grault"})},
+		{name: "homogeneous DataType",
+			input: MustNew([]interface{}{[]int{1, 2}, []int{3, 4}}, Config{Col: []string{"A", "B"}}),
+			want:  MustNew([]interface{}{[]int{1, 3}, []int{2, 4}}, Config{Index: []string{"A", "B"}})},
+		{name: "int64 and float64",
+			input: MustNew([]interface{}{[]int{1}, []float64{2.5}}, Config{Col: []string{"A", "B"}}),
+			want:  MustNew([]interface{}{[]float64{1, 2.5}}, Config{Index: []string{"A", "B"}})},
+		{name: "mixed DataTypes",
+			input: MustNew([]interface{}{[]int{1}, []string{"foo"}}, Config{Col: []string{"A", "B"}}),
+			want:  MustNew([]interface{}{[]interface{}{int64(1), "foo"}}, Config{Index: []string{"A", "B"}})},
+		{name: "empty", input: newEmptyDataFrame(), want: newEmptyDataFrame()},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -209,36 +205,31 @@ func TestTranspose(t *testing.T) {
 	}
 }
 
-func TestTransposeSeries(t *testing.T) {
-	multi := MustNew([]interface{}{0, 1, 2}, Config{MultiIndex: []interface{}{"qux"}, MultiCol: [][]string{{"foo", "bar", "baz"}, {"4", "5", "6"}}})
-	multi.cols.Levels[1].DataType = options.Int64
-
-	type args struct {
-		s *series.Series
+func TestTranspose_roundTrip(t *testing.T) {
+	df := MustNew([]interface{}{[]int{1, 2}, []int{3, 4}, []int{5, 6}},
+		Config{MultiIndex: []interface{}{[]string{"foo", "bar"}, []int{10, 20}}, MultiIndexNames: []string{"qux", "quux"},
+			MultiCol: [][]string{{"A", "A", "B"}, {"x", "y", "x"}}, MultiColNames: []string{"corge", "grault"}})
+	transposed := df.Transpose()
+	if transposed.IndexLevels() != 2 || transposed.ColLevels() != 2 {
+		t.Errorf("DataFrame.Transpose() levels = %v index, %v columns, want 2, 2", transposed.IndexLevels(), transposed.ColLevels())
 	}
-	tests := []struct {
-		name string
-		args args
-		want *DataFrame
-	}{
-		{name: "single index", args: args{s: series.MustNew([]int{0, 1, 4},
-			series.Config{Index: []string{"foo", "bar", "baz"}, Name: "qux"})},
-			want: MustNew([]interface{}{0, 1, 4}, Config{MultiIndex: []interface{}{"qux"}, Col: []string{"foo", "bar", "baz"}})},
-		{name: "multi index", args: args{s: series.MustNew([]int{0, 1, 2},
-			series.Config{MultiIndex: []interface{}{[]string{"foo", "bar", "baz"}, []int{4, 5, 6}}, Name: "qux"})},
-			want: multi},
-		{name: "int in name to int index", args: args{s: series.MustNew([]int{0, 1, 2},
-			series.Config{Index: []string{"foo", "bar", "baz"}, Name: "0"})},
-			want: MustNew([]interface{}{0, 1, 2}, Config{Index: 0, Col: []string{"foo", "bar", "baz"}})},
+	if transposed.vals[0].DataType != options.Int64 {
+		t.Errorf("DataFrame.Transpose() DataType = %v, want int64", transposed.vals[0].DataType)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := transposeSeries(tt.args.s)
-			if !Equal(got, tt.want) {
-				t.Errorf("transposeSeries() = %v, want %v", got, tt.want)
-			}
-
-		})
+	got := transposed.Transpose()
+	if !Equal(got, df) {
+		t.Errorf("DataFrame.Transpose().Transpose() = %v, want %v", got, df)
+	}
+	for j := 0; j < df.IndexLevels(); j++ {
+		if got.index.Levels[j].Name != df.index.Levels[j].Name || got.index.Levels[j].DataType != df.index.Levels[j].DataType {
+			t.Errorf("DataFrame.Transpose().Transpose() index level %d = %v %v, want %v %v", j,
+				got.index.Levels[j].Name, got.index.Levels[j].DataType, df.index.Levels[j].Name, df.index.Levels[j].DataType)
+		}
+	}
+	for j := 0; j < df.ColLevels(); j++ {
+		if got.cols.Levels[j].Name != df.cols.Levels[j].Name {
+			t.Errorf("DataFrame.Transpose().Transpose() column level %d name = %v, want %v", j, got.cols.Levels[j].Name, df.cols.Levels[j].Name)
+		}
 	}
 }
 
@@ -252,10 +243,10 @@ func TestDataFrame_Pivot(t *testing.T) {
 		Config{Col: []string{"A", "B", "C"}})
 
 	type args struct {
-		index   int
-		data    int
-		col     int
-		aggFunc string
+		index int
+		data  int
+		col   int
+		agg   series.Agg
 	}
 	tests := []struct {
 		name    string
@@ -266,46 +257,60 @@ func TestDataFrame_Pivot(t *testing.T) {
 	}{
 		{name: "NaN: sum",
 			input: multi,
-			args:  args{index: 0, data: 3, col: 1, aggFunc: "sum"},
+			args:  args{index: 0, data: 3, col: 1, agg: series.AggSum},
 			want: MustNew([]interface{}{[]string{"3", "3"}, []string{"NaN", "4"}},
 				Config{Col: []string{"baz", "qux"}, Index: []string{"foo", "bar"}}),
 			wantErr: false},
 		{name: "mean",
 			input: df,
-			args:  args{index: 0, data: 2, col: 1, aggFunc: "mean"},
+			args:  args{index: 0, data: 2, col: 1, agg: series.AggMean},
 			want: MustNew([]interface{}{1.5, 3.0},
 				Config{Col: []string{"bar", "baz"}, Index: "foo"}),
 			wantErr: false},
 		{name: "median",
 			input: df,
-			args:  args{index: 0, data: 2, col: 1, aggFunc: "median"},
+			args:  args{index: 0, data: 2, col: 1, agg: series.AggMedian},
 			want: MustNew([]interface{}{1.5, 3.0},
 				Config{Col: []string{"bar", "baz"}, Index: "foo"}),
 			wantErr: false},
 		{name: "min",
 			input: df,
-			args:  args{index: 0, data: 2, col: 1, aggFunc: "min"},
+			args:  args{index: 0, data: 2, col: 1, agg: series.AggMin},
 			want: MustNew([]interface{}{1.0, 3.0},
 				Config{Col: []string{"bar", "baz"}, Index: "foo"}),
 			wantErr: false},
 		{name: "max",
 			input: df,
-			args:  args{index: 0, data: 2, col: 1, aggFunc: "max"},
+			args:  args{index: 0, data: 2, col: 1, agg: series.AggMax},
 			want: MustNew([]interface{}{2.0, 3.0},
 				Config{Col: []string{"bar", "baz"}, Index: "foo"}),
 			wantErr: false},
 		{name: "std",
 			input: df,
-			args:  args{index: 0, data: 2, col: 1, aggFunc: "std"},
+			args:  args{index: 0, data: 2, col: 1, agg: series.AggStd},
 			want: MustNew([]interface{}{0.5, 0.0},
 				Config{Col: []string{"bar", "baz"}, Index: "foo"}),
 			wantErr: false},
-		{name: "fail", input: df, args: args{index: 0, data: 2, col: 1, aggFunc: "unsupported"},
+		{name: "count",
+			input: df,
+			args:  args{index: 0, data: 2, col: 1, agg: series.AggCount},
+			want: MustNew([]interface{}{2, 1},
+				Config{Col: []string{"bar", "baz"}, Index: "foo"}),
+			wantErr: false},
+		{name: "custom string aggregation",
+			input: df,
+			args: args{index: 0, data: 1, col: 1, agg: series.NewAgg("joined", func(s *series.Series) interface{} {
+				return strings.Join(s.Vals().([]string), ",")
+			})},
+			want: MustNew([]interface{}{"bar,bar", "baz"},
+				Config{Col: []string{"bar", "baz"}, Index: "foo"}),
+			wantErr: false},
+		{name: "fail: invalid column", input: df, args: args{index: 0, data: 3, col: 1, agg: series.AggSum},
 			want: newEmptyDataFrame(), wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.input.Pivot(tt.args.index, tt.args.data, tt.args.col, tt.args.aggFunc)
+			got, err := tt.input.Pivot(tt.args.index, tt.args.data, tt.args.col, tt.args.agg)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("df.Pivot() error = %v, want %v", err, tt.wantErr)
 				return
@@ -324,3 +329,316 @@ func TestDataFrame_Pivot(t *testing.T) {
 		})
 	}
 }
+
+func TestDataFrame_Melt(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"a", "b"}, []int{1, 2}, []float64{3.5, 4}},
+		Config{Col: []string{"id", "jan", "feb"}})
+	type args struct {
+		idVars    []string
+		valueVars []string
+		varName   string
+		valueName string
+	}
+	tests := []struct {
+		name    string
+		input   *DataFrame
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "all other columns", input: df, args: args{[]string{"id"}, nil, "month", "amount"},
+			want: MustNew([]interface{}{[]string{"a", "b", "a", "b"}, []string{"jan", "jan", "feb", "feb"}, []float64{1, 2, 3.5, 4}},
+				Config{Col: []string{"id", "month", "amount"}}),
+			wantErr: false},
+		{"subset with default names", df, args{[]string{"id"}, []string{"jan"}, "", ""},
+			MustNew([]interface{}{[]string{"a", "b"}, []string{"jan", "jan"}, []int{1, 2}},
+				Config{Col: []string{"id", "variable", "value"}}),
+			false},
+		{"no id columns", df, args{nil, []string{"jan"}, "", ""},
+			MustNew([]interface{}{[]string{"jan", "jan"}, []int{1, 2}}, Config{Col: []string{"variable", "value"}}),
+			false},
+		{"multi-level columns",
+			MustNew([]interface{}{[]string{"a"}, []int{1}, []int{2}},
+				Config{MultiCol: [][]string{{"id", "score", "score"}, {"", "2019", "2020"}}, MultiColNames: []string{"", "year"}}),
+			args{[]string{"id"}, nil, "", ""},
+			MustNew([]interface{}{[]string{"a", "a"}, []string{"score", "score"}, []string{"2019", "2020"}, []int{1, 2}},
+				Config{Col: []string{"id", "variable_0", "year", "value"}}),
+			false},
+		{"fail: missing id column", df, args{[]string{"foo"}, nil, "", ""}, newEmptyDataFrame(), true},
+		{"fail: missing value column", df, args{[]string{"id"}, []string{"foo"}, "", ""}, newEmptyDataFrame(), true},
+		{"fail: no value columns", df, args{[]string{"id", "jan", "feb"}, nil, "", ""}, newEmptyDataFrame(), true},
+		{"fail: duplicate column name", df, args{[]string{"id"}, nil, "", "id"}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.Melt(tt.args.idVars, tt.args.valueVars, tt.args.varName, tt.args.valueName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.Melt() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.Melt() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFrame_Melt_mixed(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"foo"}, []int{1}}, Config{Col: []string{"A", "B"}})
+	got, err := df.Melt(nil, nil, "", "")
+	if err != nil {
+		t.Errorf("DataFrame.Melt() error: %v", err)
+	}
+	if got.vals[1].DataType != options.Interface {
+		t.Errorf("DataFrame.Melt() value DataType = %v, want %v", got.vals[1].DataType, options.Interface)
+	}
+	if got.vals[1].Values.Value(0) != "foo" || got.vals[1].Values.Value(1) != int64(1) {
+		t.Errorf("DataFrame.Melt() values = %v, want [foo 1]", got.vals[1].Values.Values())
+	}
+}
+
+func TestDataFrame_WideToLong(t *testing.T) {
+	df := MustNew([]interface{}{[]string{"a", "b"}, []int{1, 2}, []int{3, 4}, []float64{0.5, 0.25}, []int{5, 6}, []string{"x", "y"}},
+		Config{Col: []string{"id", "score_2019", "score_2020", "weight_2019", "weight_2020", "group"}})
+	type args struct {
+		stubnames []string
+		i         []string
+		j         string
+		sep       string
+		suffix    string
+	}
+	tests := []struct {
+		name    string
+		input   *DataFrame
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "numeric suffixes", input: df, args: args{[]string{"score", "weight"}, []string{"id"}, "year", "_", ""},
+			want: MustNew([]interface{}{[]string{"a", "a", "b", "b"}, []string{"x", "x", "y", "y"}, []int{2019, 2020, 2019, 2020},
+				[]int{1, 3, 2, 4}, []float64{0.5, 5, 0.25, 6}},
+				Config{Col: []string{"id", "group", "year", "score", "weight"}}),
+			wantErr: false},
+		{"one stub", df, args{[]string{"score"}, []string{"id"}, "year", "_", ""},
+			MustNew([]interface{}{[]string{"a", "a", "b", "b"}, []float64{0.5, 0.5, 0.25, 0.25}, []int{5, 5, 6, 6}, []string{"x", "x", "y", "y"},
+				[]int{2019, 2020, 2019, 2020}, []int{1, 3, 2, 4}},
+				Config{Col: []string{"id", "weight_2019", "weight_2020", "group", "year", "score"}}),
+			false},
+		{"string suffixes", df, args{[]string{"score", "weight"}, []string{"id"}, "suffix", "", `_\w+`},
+			MustNew([]interface{}{[]string{"a", "a", "b", "b"}, []string{"x", "x", "y", "y"}, []string{"_2019", "_2020", "_2019", "_2020"},
+				[]int{1, 3, 2, 4}, []float64{0.5, 5, 0.25, 6}},
+				Config{Col: []string{"id", "group", "suffix", "score", "weight"}}),
+			false},
+		{"fail: stubs do not line up",
+			MustNew([]interface{}{[]string{"a"}, []int{1}, []int{3}, []float64{0.5}}, Config{Col: []string{"id", "score_2019", "score_2020", "weight_2019"}}),
+			args{[]string{"score", "weight"}, []string{"id"}, "year", "_", ""},
+			newEmptyDataFrame(), true},
+		{"fail: duplicate ids",
+			MustNew([]interface{}{[]string{"a", "a"}, []int{1, 2}}, Config{Col: []string{"id", "score_2019"}}),
+			args{[]string{"score"}, []string{"id"}, "year", "_", ""}, newEmptyDataFrame(), true},
+		{"fail: no matching columns", df, args{[]string{"height"}, []string{"id"}, "year", "_", ""}, newEmptyDataFrame(), true},
+		{"fail: missing id column", df, args{[]string{"score"}, []string{"foo"}, "year", "_", ""}, newEmptyDataFrame(), true},
+		{"fail: no stubs", df, args{nil, []string{"id"}, "year", "_", ""}, newEmptyDataFrame(), true},
+		{"fail: no j", df, args{[]string{"score"}, []string{"id"}, "", "_", ""}, newEmptyDataFrame(), true},
+		{"fail: invalid suffix", df, args{[]string{"score"}, []string{"id"}, "year", "_", "("}, newEmptyDataFrame(), true},
+		{"fail: duplicate column name", df, args{[]string{"score"}, []string{"id"}, "group", "_", ""}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.WideToLong(tt.args.stubnames, tt.args.i, tt.args.j, tt.args.sep, tt.args.suffix)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.WideToLong() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.WideToLong() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFrame_Explode(t *testing.T) {
+	df := MustNew([]interface{}{
+		[]interface{}{[]string{"a", "b"}, []string{}, "c"},
+		[]interface{}{[]int{1, 2}, 5, 3},
+		[]string{"foo", "bar", "baz"}},
+		Config{Col: []string{"x", "y", "z"}, Index: []string{"r0", "r1", "r2"}})
+	tests := []struct {
+		name    string
+		input   *DataFrame
+		cols    []string
+		want    *DataFrame
+		wantErr bool
+	}{
+		{"one column", df, []string{"x"},
+			MustNew([]interface{}{
+				[]string{"a", "b", "", "c"},
+				[]interface{}{[]int{1, 2}, []int{1, 2}, 5, 3},
+				[]string{"foo", "foo", "bar", "baz"}},
+				Config{Col: []string{"x", "y", "z"}, Index: []string{"r0", "r0", "r1", "r2"}}),
+			false},
+		{"multiple columns", df, []string{"x", "y"},
+			MustNew([]interface{}{
+				[]string{"a", "b", "", "c"},
+				[]int{1, 2, 5, 3},
+				[]string{"foo", "foo", "bar", "baz"}},
+				Config{Col: []string{"x", "y", "z"}, Index: []string{"r0", "r0", "r1", "r2"}}),
+			false},
+		{"fail: no columns", df, nil, newEmptyDataFrame(), true},
+		{"fail: missing column", df, []string{"foo"}, newEmptyDataFrame(), true},
+		{"fail: mismatched list lengths",
+			MustNew([]interface{}{[]interface{}{[]string{"a", "b"}}, []interface{}{[]int{1}}}, Config{Col: []string{"x", "y"}}),
+			[]string{"x", "y"}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.Explode(tt.cols...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.Explode() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.Explode() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataFrame_PivotTable(t *testing.T) {
+	df := MustNew([]interface{}{
+		[]string{"N", "N", "S", "S", "N"}, []string{"a", "b", "a", "a", "a"}, []int{1, 2, 3, 4, 5}},
+		Config{Col: []string{"region", "product", "sales"}})
+	salesCols := func(n int) []string {
+		ret := make([]string, n)
+		for i := range ret {
+			ret[i] = "sales"
+		}
+		return ret
+	}
+	tests := []struct {
+		name    string
+		input   *DataFrame
+		config  PivotOptions
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "multiple aggregations with fill value", input: df,
+			config: PivotOptions{Index: []string{"region"}, Columns: []string{"product"}, Values: []string{"sales"},
+				AggFuncs: map[string][]series.Agg{"sales": {series.AggSum, series.AggCount}}, FillValue: 0},
+			want: MustNew([]interface{}{[]float64{6, 7}, []float64{2, 0}, []int{2, 2}, []int{1, 0}},
+				Config{Index: []string{"N", "S"}, IndexName: "region",
+					MultiCol:      [][]string{salesCols(4), {"sum", "sum", "count", "count"}, {"a", "b", "a", "b"}},
+					MultiColNames: []string{"", "", "product"}}),
+			wantErr: false},
+		{"margins", df,
+			PivotOptions{Index: []string{"region"}, Columns: []string{"product"}, Values: []string{"sales"},
+				AggFuncs: map[string][]series.Agg{"sales": {series.AggSum}}, FillValue: 0, Margins: true},
+			MustNew([]interface{}{[]float64{6, 7, 13}, []float64{2, 0, 2}, []float64{8, 7, 15}},
+				Config{Index: []string{"N", "S", "All"}, IndexName: "region",
+					MultiCol:      [][]string{salesCols(3), {"sum", "sum", "sum"}, {"a", "b", "All"}},
+					MultiColNames: []string{"", "", "product"}}),
+			false},
+		{"default values and mean", MustNew([]interface{}{[]string{"N", "N", "S", "S", "N"}, []int{1, 2, 3, 4, 5}},
+			Config{Col: []string{"region", "sales"}}),
+			PivotOptions{Index: []string{"region"}},
+			MustNew([]interface{}{[]float64{8.0 / 3, 3.5}},
+				Config{Index: []string{"N", "S"}, IndexName: "region", MultiCol: [][]string{{"sales"}, {"mean"}}}),
+			false},
+		{"non-numeric aggregations", df,
+			PivotOptions{Index: []string{"region"}, Values: []string{"product"},
+				AggFuncs: map[string][]series.Agg{"product": {series.AggFirst, series.AggLast, series.AggNUnique}}},
+			MustNew([]interface{}{[]string{"a", "a"}, []string{"a", "a"}, []int{2, 1}},
+				Config{Index: []string{"N", "S"}, IndexName: "region",
+					MultiCol: [][]string{{"product", "product", "product"}, {"first", "last", "nunique"}}}),
+			false},
+		{"multiple index columns", df,
+			PivotOptions{Index: []string{"region", "product"}, AggFuncs: map[string][]series.Agg{"sales": {series.AggMax}}},
+			MustNew([]interface{}{[]float64{5, 2, 4}},
+				Config{MultiIndex: []interface{}{[]string{"N", "N", "S"}, []string{"a", "b", "a"}},
+					MultiIndexNames: []string{"region", "product"}, MultiCol: [][]string{{"sales"}, {"max"}}}),
+			false},
+		{"fail: no index", df, PivotOptions{}, newEmptyDataFrame(), true},
+		{"fail: missing column", df, PivotOptions{Index: []string{"foo"}}, newEmptyDataFrame(), true},
+		{"fail: value is index", df, PivotOptions{Index: []string{"region"}, Values: []string{"region"}},
+			newEmptyDataFrame(), true},
+		{"fail: aggregation for non-value column", df,
+			PivotOptions{Index: []string{"region"}, Values: []string{"sales"},
+				AggFuncs: map[string][]series.Agg{"product": {series.AggSum}}},
+			newEmptyDataFrame(), true},
+		{"fail: no value columns", df, PivotOptions{Index: []string{"region"}, Columns: []string{"product", "sales"}},
+			newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.PivotTable(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DataFrame.PivotTable() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("DataFrame.PivotTable() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync, _ := tt.input.PivotTable(tt.config)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("DataFrame.PivotTable() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrosstab(t *testing.T) {
+	gender := series.MustNew([]string{"M", "F", "M", "F", "M"}, series.Config{Name: "gender"})
+	smoker := series.MustNew([]string{"y", "n", "n", "n", "y"}, series.Config{Name: "smoker"})
+	type args struct {
+		rows   *series.Series
+		cols   *series.Series
+		config CrosstabOptions
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *DataFrame
+		wantErr bool
+	}{
+		{name: "frequency", args: args{gender, smoker, CrosstabOptions{}},
+			want: MustNew([]interface{}{[]int{2, 0}, []int{1, 2}},
+				Config{Index: []string{"M", "F"}, IndexName: "gender", Col: []string{"y", "n"}, ColName: "smoker"}),
+			wantErr: false},
+		{"margins", args{gender, smoker, CrosstabOptions{Margins: true}},
+			MustNew([]interface{}{[]int{2, 0, 2}, []int{1, 2, 3}, []int{3, 2, 5}},
+				Config{Index: []string{"M", "F", "All"}, IndexName: "gender", Col: []string{"y", "n", "All"}, ColName: "smoker"}),
+			false},
+		{"normalize all", args{gender, smoker, CrosstabOptions{Normalize: "all"}},
+			MustNew([]interface{}{[]float64{0.4, 0}, []float64{0.2, 0.4}},
+				Config{Index: []string{"M", "F"}, IndexName: "gender", Col: []string{"y", "n"}, ColName: "smoker"}),
+			false},
+		{"normalize index", args{gender, smoker, CrosstabOptions{Normalize: "index"}},
+			MustNew([]interface{}{[]float64{2.0 / 3, 0}, []float64{1.0 / 3, 1}},
+				Config{Index: []string{"M", "F"}, IndexName: "gender", Col: []string{"y", "n"}, ColName: "smoker"}),
+			false},
+		{"normalize columns with margins", args{gender, smoker, CrosstabOptions{Normalize: "columns", Margins: true}},
+			MustNew([]interface{}{[]float64{1, 0, 1}, []float64{1.0 / 3, 2.0 / 3, 1}, []float64{0.6, 0.4, 1}},
+				Config{Index: []string{"M", "F", "All"}, IndexName: "gender", Col: []string{"y", "n", "All"}, ColName: "smoker"}),
+			false},
+		{"values", args{series.MustNew([]string{"a", "a", "b", "b"}), series.MustNew([]string{"x", "y", "x", "y"}),
+			CrosstabOptions{Values: series.MustNew([]int{1, 2, 3, 4}), AggFunc: series.AggSum}},
+			MustNew([]interface{}{[]float64{1, 3}, []float64{2, 4}}, Config{Index: []string{"a", "b"}, Col: []string{"x", "y"}}),
+			false},
+		{"aligned on index with null labels dropped",
+			args{series.MustNew([]string{"a", "b", "c"}), series.MustNew([]string{"x", "y", "z"}, series.Config{Index: []int{1, 2, 3}}),
+				CrosstabOptions{DropNA: true}},
+			MustNew([]interface{}{[]int{1, 0}, []int{0, 1}}, Config{Index: []string{"b", "c"}, Col: []string{"x", "y"}}),
+			false},
+		{"fail: values without aggregation", args{gender, smoker, CrosstabOptions{Values: gender}}, newEmptyDataFrame(), true},
+		{"fail: invalid normalize", args{gender, smoker, CrosstabOptions{Normalize: "foo"}}, newEmptyDataFrame(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Crosstab(tt.args.rows, tt.args.cols, tt.args.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Crosstab() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Crosstab() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -86,6 +86,28 @@ func (df *DataFrame) SelectCol(label string) int {
 	return val[0]
 }
 
+// colPositions returns the integer location of the first column in column level 0 with each of the supplied labels,
+// or an error listing the labels that do not exist.
+func (df *DataFrame) colPositions(labels []string) ([]int, error) {
+	if df.ColLevels() == 0 {
+		return nil, fmt.Errorf("columns have no levels")
+	}
+	positions := make([]int, len(labels))
+	var missing []string
+	for i, label := range labels {
+		val, ok := df.cols.Levels[0].LabelMap[label]
+		if !ok {
+			missing = append(missing, label)
+			continue
+		}
+		positions[i] = val[0]
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("columns not found: %v", missing)
+	}
+	return positions, nil
+}
+
 // SelectCols returns the integer locations of all columns with the supplied labels within the supplied level.
 // If an error is encountered, returns a new slice of 0 length.
 func (df *DataFrame) SelectCols(labels []string, level int) []int {
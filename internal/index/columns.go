@@ -159,6 +159,65 @@ func (col *Columns) Refresh() {
 	}
 }
 
+// key returns a string that uniquely identifies the labels of the column at every level, for use as a hash key.
+func (col Columns) key(column int) string {
+	var key string
+	for j := 0; j < col.NumLevels(); j++ {
+		key += values.Key(col.Levels[j].Labels[column])
+	}
+	return key
+}
+
+// Align returns the union of the columns in col and col2, plus the position of each union column within col and within col2 (or -1 if absent).
+// Columns from col come first in their original order, followed by the columns only in col2.
+// Columns are matched by their labels at every level; duplicate columns are paired in their order of appearance.
+func (col Columns) Align(col2 Columns) (Columns, []int, []int, error) {
+	if col.NumLevels() != col2.NumLevels() {
+		return Columns{}, nil, nil, fmt.Errorf("columns.Align(): col2 must have same number of levels as col (%d != %d)",
+			col2.NumLevels(), col.NumLevels())
+	}
+	table := make(map[string][]int, col2.Len())
+	for k := 0; k < col2.Len(); k++ {
+		key := col2.key(k)
+		table[key] = append(table[key], k)
+	}
+	leftPositions := make([]int, col.Len())
+	rightPositions := make([]int, col.Len())
+	matched := make([]bool, col2.Len())
+	occurrences := make(map[string]int)
+	for k := 0; k < col.Len(); k++ {
+		leftPositions[k] = k
+		rightPositions[k] = -1
+		key := col.key(k)
+		if n := occurrences[key]; n < len(table[key]) {
+			rightPositions[k] = table[key][n]
+			matched[table[key][n]] = true
+		}
+		occurrences[key]++
+	}
+	var extra []int
+	for k := 0; k < col2.Len(); k++ {
+		if !matched[k] {
+			extra = append(extra, k)
+			leftPositions = append(leftPositions, -1)
+			rightPositions = append(rightPositions, k)
+		}
+	}
+	if len(extra) == 0 {
+		return col.Copy(), leftPositions, rightPositions, nil
+	}
+	levels := make([]ColLevel, col.NumLevels())
+	for j := 0; j < col.NumLevels(); j++ {
+		labels := make([]string, col.Len(), col.Len()+len(extra))
+		copy(labels, col.Levels[j].Labels)
+		for _, k := range extra {
+			labels = append(labels, col2.Levels[j].Labels[k])
+		}
+		levels[j] = NewColLevel(labels, col.Levels[j].Name)
+	}
+	return NewColumns(levels...), leftPositions, rightPositions, nil
+}
+
 // [START Columns modification methods]
 
 // returns an error if any level position does not exist
@@ -247,6 +247,28 @@ func TestColumns_SubsetLevels(t *testing.T) {
 	}
 }
 
+func TestColumns_Align(t *testing.T) {
+	cols := CreateMultiCol([][]string{{"A", "A"}, {"1", "2"}}, []string{"foo", "bar"})
+	cols2 := CreateMultiCol([][]string{{"A", "B"}, {"2", "1"}}, []string{"baz", "qux"})
+	got, gotLeft, gotRight, err := cols.Align(cols2)
+	if err != nil {
+		t.Errorf("Columns.Align() error: %v", err)
+	}
+	want := CreateMultiCol([][]string{{"A", "A", "B"}, {"1", "2", "1"}}, []string{"foo", "bar"})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns.Align() got %v, want %v", got, want)
+	}
+	if wantLeft := []int{0, 1, -1}; !reflect.DeepEqual(gotLeft, wantLeft) {
+		t.Errorf("Columns.Align() left positions got %v, want %v", gotLeft, wantLeft)
+	}
+	if wantRight := []int{-1, 0, 1}; !reflect.DeepEqual(gotRight, wantRight) {
+		t.Errorf("Columns.Align() right positions got %v, want %v", gotRight, wantRight)
+	}
+	if _, _, _, err := cols.Align(NewDefaultColumns(1)); err == nil {
+		t.Errorf("Columns.Align() returned nil error, want error due to mismatched levels")
+	}
+}
+
 func TestNewColLevel(t *testing.T) {
 	got := NewColLevel([]string{"foo", "bar"}, "foobar")
 	want := ColLevel{
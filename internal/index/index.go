@@ -246,6 +246,15 @@ func (idx Index) Elements(position int) Elements {
 	return Elements{labels, datatypes}
 }
 
+// Key returns a string that uniquely identifies the typed labels across all levels, for use as a hash key.
+func (elems Elements) Key() string {
+	var key string
+	for _, label := range elems.Labels {
+		key += values.Key(label)
+	}
+	return key
+}
+
 // [END Element]
 
 // [START Index]
@@ -258,6 +267,20 @@ func (idx Index) Len() int {
 	return idx.Levels[0].Len()
 }
 
+// Factorize returns the group code of every row in idx, numbered in order of first appearance, and the number of codes.
+// Rows share a code if and only if they share a label in every level (see values.Factorize).
+func (idx Index) Factorize() ([]int, int) {
+	if idx.NumLevels() == 0 {
+		return nil, 0
+	}
+	codes, numCodes := values.Factorize(idx.Levels[0].Labels)
+	for j := 1; j < idx.NumLevels(); j++ {
+		levelCodes, numLevelCodes := values.Factorize(idx.Levels[j].Labels)
+		codes, numCodes = values.CombineCodes(codes, numCodes, levelCodes, numLevelCodes)
+	}
+	return codes, numCodes
+}
+
 // NumLevels returns the number of levels in the index.
 func (idx Index) NumLevels() int {
 	return len(idx.Levels)
@@ -306,6 +329,98 @@ func (idx Index) Aligned() error {
 	return nil
 }
 
+// LookupPositions returns the position within idx2 of the labels at every row of idx, or -1 if the labels are not in idx2.
+// Labels are matched across all levels by type and value. duplicates determines which position is returned
+// when the same labels appear more than once in idx2: "first", "last", or "error".
+func (idx Index) LookupPositions(idx2 Index, duplicates string) ([]int, error) {
+	if idx.NumLevels() != idx2.NumLevels() {
+		return nil, fmt.Errorf("index.LookupPositions(): idx2 must have same number of levels as idx (%d != %d)",
+			idx2.NumLevels(), idx.NumLevels())
+	}
+	if duplicates == "" {
+		duplicates = "first"
+	}
+	if duplicates != "first" && duplicates != "last" && duplicates != "error" {
+		return nil, fmt.Errorf("index.LookupPositions(): duplicates must be first, last, or error, not %q", duplicates)
+	}
+	table := make(map[string]int, idx2.Len())
+	for i := 0; i < idx2.Len(); i++ {
+		key := idx2.Elements(i).Key()
+		if _, ok := table[key]; ok {
+			if duplicates == "error" {
+				return nil, fmt.Errorf("index.LookupPositions(): duplicate labels in idx2: %v", idx2.Elements(i).Labels)
+			}
+			if duplicates == "first" {
+				continue
+			}
+		}
+		table[key] = i
+	}
+	positions := make([]int, idx.Len())
+	for i := 0; i < idx.Len(); i++ {
+		pos, ok := table[idx.Elements(i).Key()]
+		if !ok {
+			pos = -1
+		}
+		positions[i] = pos
+	}
+	return positions, nil
+}
+
+// Align returns the union of the labels in idx and idx2, plus the position of each union row within idx and within idx2 (or -1 if absent).
+// Rows from idx come first in their original order, followed by the rows only in idx2.
+// Labels are matched across all levels by type and value; duplicate labels are paired in their order of appearance.
+// Union levels retain the names of idx and are converted to interface if the DataTypes of idx and idx2 differ.
+func (idx Index) Align(idx2 Index) (Index, []int, []int, error) {
+	if idx.NumLevels() != idx2.NumLevels() {
+		return Index{}, nil, nil, fmt.Errorf("index.Align(): idx2 must have same number of levels as idx (%d != %d)",
+			idx2.NumLevels(), idx.NumLevels())
+	}
+	table := make(map[string][]int, idx2.Len())
+	for i := 0; i < idx2.Len(); i++ {
+		key := idx2.Elements(i).Key()
+		table[key] = append(table[key], i)
+	}
+	leftPositions := make([]int, idx.Len())
+	rightPositions := make([]int, idx.Len())
+	matched := make([]bool, idx2.Len())
+	occurrences := make(map[string]int)
+	for i := 0; i < idx.Len(); i++ {
+		leftPositions[i] = i
+		rightPositions[i] = -1
+		key := idx.Elements(i).Key()
+		if n := occurrences[key]; n < len(table[key]) {
+			rightPositions[i] = table[key][n]
+			matched[table[key][n]] = true
+		}
+		occurrences[key]++
+	}
+	var extra []int
+	for i := 0; i < idx2.Len(); i++ {
+		if !matched[i] {
+			extra = append(extra, i)
+			leftPositions = append(leftPositions, -1)
+			rightPositions = append(rightPositions, i)
+		}
+	}
+
+	levels := make([]Level, idx.NumLevels())
+	for j := 0; j < idx.NumLevels(); j++ {
+		dataType := idx.Levels[j].DataType
+		labels := idx.Levels[j].Labels.Copy()
+		if len(extra) > 0 {
+			if idx2.Levels[j].DataType != dataType {
+				dataType = options.Interface
+				labels = labels.ToInterface()
+			}
+			labels.Append(idx2.Levels[j].Labels.Subset(extra))
+		}
+		levels[j] = Level{Labels: labels, DataType: dataType, Name: idx.Levels[j].Name,
+			IsDefault: idx.Levels[j].IsDefault && len(extra) == 0, NeedsRefresh: true}
+	}
+	return New(levels...), leftPositions, rightPositions, nil
+}
+
 // SwapLevels swaps two levels in the index and modifies the index in place.
 func (idx *Index) SwapLevels(i, j int) error {
 	if err := idx.ensureLevelPositions([]int{i}); err != nil {
@@ -334,6 +334,86 @@ func TestElements(t *testing.T) {
 	}
 }
 
+func TestElements_Key(t *testing.T) {
+	got := New(MustNewLevel([]string{"1 | 2"}, ""), MustNewLevel([]string{"3"}, "")).Elements(0).Key()
+	got2 := New(MustNewLevel([]string{"1"}, ""), MustNewLevel([]string{"2 | 3"}, "")).Elements(0).Key()
+	if got == got2 {
+		t.Errorf("Elements.Key(): different labels returned the same key %v", got)
+	}
+	got = New(MustNewLevel([]int{1}, "")).Elements(0).Key()
+	got2 = New(MustNewLevel([]string{"1"}, "")).Elements(0).Key()
+	if got == got2 {
+		t.Errorf("Elements.Key(): labels of different types returned the same key %v", got)
+	}
+}
+
+func TestIndex_LookupPositions(t *testing.T) {
+	idx := New(MustNewLevel([]string{"foo", "bar", "baz"}, ""), MustNewLevel([]int{1, 2, 3}, ""))
+	type args struct {
+		idx2       Index
+		duplicates string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []int
+		wantErr bool
+	}{
+		{"pass", args{New(MustNewLevel([]string{"baz", "foo"}, ""), MustNewLevel([]int{3, 1}, "")), "first"},
+			[]int{1, -1, 0}, false},
+		{"default duplicates: first", args{New(MustNewLevel([]string{"foo", "foo"}, ""), MustNewLevel([]int{1, 1}, "")), ""},
+			[]int{0, -1, -1}, false},
+		{"duplicates: last", args{New(MustNewLevel([]string{"foo", "foo"}, ""), MustNewLevel([]int{1, 1}, "")), "last"},
+			[]int{1, -1, -1}, false},
+		{"fail: duplicates: error", args{New(MustNewLevel([]string{"foo", "foo"}, ""), MustNewLevel([]int{1, 1}, "")), "error"},
+			nil, true},
+		{"fail: invalid duplicates", args{idx, "corge"},
+			nil, true},
+		{"fail: levels", args{New(MustNewLevel([]string{"foo"}, "")), "first"},
+			nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := idx.LookupPositions(tt.args.idx2, tt.args.duplicates)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Index.LookupPositions() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Index.LookupPositions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndex_Align(t *testing.T) {
+	idx := New(MustNewLevel([]string{"foo", "bar", "foo"}, "a"))
+	idx2 := New(MustNewLevel([]string{"baz", "foo"}, "b"))
+	got, gotLeft, gotRight, err := idx.Align(idx2)
+	if err != nil {
+		t.Errorf("Index.Align() error: %v", err)
+	}
+	want := New(Level{Labels: MustNewLevel([]string{"foo", "bar", "foo", "baz"}, "").Labels, DataType: options.String, Name: "a", NeedsRefresh: true})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Index.Align() got %v, want %v", got, want)
+	}
+	if wantLeft := []int{0, 1, 2, -1}; !reflect.DeepEqual(gotLeft, wantLeft) {
+		t.Errorf("Index.Align() left positions got %v, want %v", gotLeft, wantLeft)
+	}
+	if wantRight := []int{1, -1, -1, 0}; !reflect.DeepEqual(gotRight, wantRight) {
+		t.Errorf("Index.Align() right positions got %v, want %v", gotRight, wantRight)
+	}
+
+	got, _, _, _ = idx.Align(New(MustNewLevel([]int{1}, "")))
+	if got.Levels[0].DataType != options.Interface {
+		t.Errorf("Index.Align() mixed DataTypes got %v, want %v", got.Levels[0].DataType, options.Interface)
+	}
+
+	if _, _, _, err := idx.Align(New(MustNewLevel("foo", ""), MustNewLevel("bar", ""))); err == nil {
+		t.Errorf("Index.Align() returned nil error, want error due to mismatched levels")
+	}
+}
+
 func TestAligned(t *testing.T) {
 	vals, _ := values.InterfaceFactory([]int{1})
 	labels1 := vals.Values
@@ -853,3 +933,25 @@ func TestConvert_Unsupported(t *testing.T) {
 		}
 	}
 }
+
+func TestIndex_Factorize(t *testing.T) {
+	tests := []struct {
+		name      string
+		idx       Index
+		wantCodes []int
+		wantN     int
+	}{
+		{"no levels", New(), nil, 0},
+		{"one level", New(MustNewLevel([]string{"foo", "bar", "foo"}, "")), []int{0, 1, 0}, 2},
+		{"multiple levels", New(MustNewLevel([]string{"foo", "foo", "bar", "foo"}, ""), MustNewLevel([]int{1, 2, 1, 1}, "")),
+			[]int{0, 1, 2, 0}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCodes, gotN := tt.idx.Factorize()
+			if !reflect.DeepEqual(gotCodes, tt.wantCodes) || gotN != tt.wantN {
+				t.Errorf("Index.Factorize() = %v, %v, want %v, %v", gotCodes, gotN, tt.wantCodes, tt.wantN)
+			}
+		})
+	}
+}
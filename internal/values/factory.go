@@ -3,7 +3,9 @@ package values
 import (
 	"fmt"
 	"log"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"time"
 
@@ -391,3 +393,258 @@ func TransposeValues(data [][]interface{}) []interface{} {
 	}
 	return ret
 }
+
+// MakeNullContainer returns a Container of n null values of the specified DataType.
+func MakeNullContainer(n int, dataType options.DataType) Container {
+	// ducks error because []interface{} is supported
+	container, _ := InterfaceFactory(MakeNullRange(n))
+	if dataType == options.None || dataType == options.Interface {
+		return container
+	}
+	// ducks error because dataType is a supported kind
+	container.Values, _ = Convert(container.Values, dataType)
+	container.DataType = dataType
+	return container
+}
+
+// Lookup returns a new Container with the values located at the specified integer positions.
+// A position of -1 is filled with a null value of the Container's DataType.
+func (vc Container) Lookup(positions []int) Container {
+	if vc.Values == nil || vc.Values.Len() == 0 {
+		return MakeNullContainer(len(positions), vc.DataType)
+	}
+	var misses []int
+	subset := make([]int, len(positions))
+	for i, pos := range positions {
+		if pos == -1 {
+			misses = append(misses, i)
+			continue
+		}
+		subset[i] = pos
+	}
+	vals := vc.Values.Subset(subset)
+	for _, i := range misses {
+		vals.Set(i, "")
+	}
+	return Container{Values: vals, DataType: vc.DataType}
+}
+
+// CommonDataType returns the DataType that can hold values of every DataType supplied:
+// the DataType itself if they are all the same, Float64 if they mix Float64 and Int64, and Interface otherwise.
+// None is ignored, and returned only if no other DataType is supplied.
+func CommonDataType(dataTypes ...options.DataType) options.DataType {
+	ret := options.None
+	for _, dataType := range dataTypes {
+		switch {
+		case dataType == options.None || dataType == ret:
+		case ret == options.None:
+			ret = dataType
+		case (ret == options.Float64 || ret == options.Int64) &&
+			(dataType == options.Float64 || dataType == options.Int64):
+			ret = options.Float64
+		default:
+			return options.Interface
+		}
+	}
+	return ret
+}
+
+// ScalarSliceFactory creates a Container from a slice of scalars, using the CommonDataType of the non-nil scalars.
+// nil scalars are null. Unsupported scalars are held as interface.
+func ScalarSliceFactory(data []interface{}) Container {
+	dataTypes := make([]options.DataType, 0, len(data))
+	for _, v := range data {
+		if v == nil {
+			continue
+		}
+		container, err := ScalarFactory(v)
+		if err != nil {
+			dataTypes = append(dataTypes, options.Interface)
+			continue
+		}
+		dataTypes = append(dataTypes, container.DataType)
+	}
+	ret := MakeNullContainer(len(data), CommonDataType(dataTypes...))
+	for i, v := range data {
+		if v != nil {
+			ret.Values.Set(i, v)
+		}
+	}
+	return ret
+}
+
+// Explode returns a Container with one value for every element of the slices in vc,
+// plus the position in vc of each returned value.
+// Values that are not slices are returned as is, and null values and empty slices are returned as a single null.
+// The returned Container has the common DataType of the non-null values (see ScalarSliceFactory).
+func (vc Container) Explode() (Container, []int) {
+	var data []interface{}
+	var positions []int
+	for i := 0; i < vc.Values.Len(); i++ {
+		if vc.Values.Null(i) {
+			data = append(data, nil)
+			positions = append(positions, i)
+			continue
+		}
+		v := vc.Values.Value(i)
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			data = append(data, v)
+			positions = append(positions, i)
+			continue
+		}
+		if rv.Len() == 0 {
+			data = append(data, nil)
+			positions = append(positions, i)
+			continue
+		}
+		for k := 0; k < rv.Len(); k++ {
+			data = append(data, rv.Index(k).Interface())
+			positions = append(positions, i)
+		}
+	}
+	return ScalarSliceFactory(data), positions
+}
+
+// Accumulate returns a Container in which each value is the cumulative op ("sum", "prod", "max" or "min")
+// of the non-null values in its group, up to and including the value, in the order of the group's positions.
+// The result is int64 if vc is int64 and op is "sum", "max" or "min", and float64 otherwise, so that products do not overflow.
+// If an int64 sum overflows, it and the rest of its group are null, as in int64 arithmetic (see AddInt64).
+// groups are the positions in vc of the values in each group. Null values and values in no group are null.
+// Returns an error if vc is not float64 or int64, or op is unsupported.
+func (vc Container) Accumulate(groups [][]int, op string) (Container, error) {
+	if vc.DataType != options.Float64 && vc.DataType != options.Int64 {
+		return Container{}, fmt.Errorf("unable to accumulate DataType %v", vc.DataType)
+	}
+	var fn func(acc, d float64) float64
+	var intFn func(acc, d int64) (int64, bool)
+	switch op {
+	case "sum":
+		fn = func(acc, d float64) float64 { return acc + d }
+		intFn = AddInt64
+	case "prod":
+		fn = func(acc, d float64) float64 { return acc * d }
+	case "max":
+		fn = math.Max
+		intFn = func(acc, d int64) (int64, bool) {
+			if d > acc {
+				return d, true
+			}
+			return acc, true
+		}
+	case "min":
+		fn = math.Min
+		intFn = func(acc, d int64) (int64, bool) {
+			if d < acc {
+				return d, true
+			}
+			return acc, true
+		}
+	default:
+		return Container{}, fmt.Errorf("unsupported cumulative operation %q", op)
+	}
+	if vc.DataType == options.Int64 && intFn != nil {
+		return vc.accumulateInt64(groups, intFn), nil
+	}
+	floats := vc.Values.ToFloat64().Vals().([]float64)
+	ret := make([]float64, len(floats))
+	for i := range ret {
+		ret[i] = math.NaN()
+	}
+	for _, positions := range groups {
+		acc := math.NaN()
+		for _, pos := range positions {
+			if vc.Values.Null(pos) {
+				continue
+			}
+			if math.IsNaN(acc) {
+				acc = floats[pos]
+			} else {
+				acc = fn(acc, floats[pos])
+			}
+			ret[pos] = acc
+		}
+	}
+	return MustCreateValuesFromInterface(ret), nil
+}
+
+// accumulateInt64 returns an int64 Container in which each value is the cumulative fn of the non-null values in its group,
+// until fn returns false. See Accumulate.
+func (vc Container) accumulateInt64(groups [][]int, fn func(acc, d int64) (int64, bool)) Container {
+	ints := vc.Values.Vals().([]int64)
+	ret := MakeNullContainer(len(ints), options.Int64)
+	for _, positions := range groups {
+		var acc int64
+		var started bool
+		for _, pos := range positions {
+			if vc.Values.Null(pos) {
+				continue
+			}
+			if !started {
+				acc, started = ints[pos], true
+			} else {
+				var ok bool
+				if acc, ok = fn(acc, ints[pos]); !ok {
+					// the rest of the group is null
+					break
+				}
+			}
+			ret.Values.Set(pos, acc)
+		}
+	}
+	return ret
+}
+
+// Rank returns a float64 Container with the ascending rank, starting at 1, of each non-null value among the non-null values in its group.
+// groups are the positions in vc of the values in each group. Null values and values in no group are null.
+// Tied values share the average of their ranks, unless method is "min" or "max" (the lowest or highest of their ranks),
+// "first" (ranks in order of position) or "dense" (like "min", but ranks increase by 1 between distinct values).
+// Returns an error if method is unsupported.
+func (vc Container) Rank(groups [][]int, method string) (Container, error) {
+	switch method {
+	case "", "average", "min", "max", "first", "dense":
+	default:
+		return Container{}, fmt.Errorf("method must be average, min, max, first, dense, or empty, not %q", method)
+	}
+	vals := vc.Values
+	ret := make([]float64, vals.Len())
+	for i := range ret {
+		ret[i] = math.NaN()
+	}
+	for _, positions := range groups {
+		var sorted []int
+		for _, pos := range positions {
+			if !vals.Null(pos) {
+				sorted = append(sorted, pos)
+			}
+		}
+		sort.SliceStable(sorted, func(i, j int) bool { return vals.Less(sorted[i], sorted[j]) })
+		var dense float64
+		for start := 0; start < len(sorted); {
+			// values in sorted[start:end] are tied
+			end := start + 1
+			for end < len(sorted) && !vals.Less(sorted[start], sorted[end]) {
+				end++
+			}
+			dense++
+			for k := start; k < end; k++ {
+				var rank float64
+				switch method {
+				case "min":
+					rank = float64(start + 1)
+				case "max":
+					rank = float64(end)
+				case "first":
+					rank = float64(k + 1)
+				case "dense":
+					rank = dense
+				default:
+					rank = float64(start+1+end) / 2
+				}
+				ret[sorted[k]] = rank
+			}
+			start = end
+		}
+	}
+	return MustCreateValuesFromInterface(ret), nil
+}
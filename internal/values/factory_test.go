@@ -2,6 +2,7 @@ package values
 
 import (
 	"bytes"
+	"fmt"
 	"log"
 	"math"
 	"os"
@@ -495,6 +496,218 @@ func TestContainer_Copy(t *testing.T) {
 	}
 }
 
+func TestMakeNullContainer(t *testing.T) {
+	got := MakeNullContainer(2, options.Int64)
+	want := Container{&int64Values{int64Value{0, true}, int64Value{0, true}}, options.Int64}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MakeNullContainer(): got %v, want %v", got, want)
+	}
+}
+
+func TestContainer_Lookup(t *testing.T) {
+	tests := []struct {
+		name      string
+		container Container
+		positions []int
+		want      Container
+	}{
+		{"pass", MustCreateValuesFromInterface([]int{1, 2}), []int{1, -1, 0},
+			Container{&int64Values{int64Value{2, false}, int64Value{0, true}, int64Value{1, false}}, options.Int64}},
+		{"empty", Container{&int64Values{}, options.Int64}, []int{-1},
+			Container{&int64Values{int64Value{0, true}}, options.Int64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.container.Lookup(tt.positions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Container.Lookup(): got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key(1) == Key("1") {
+		t.Errorf("Key(): values of different types returned the same key %v", Key(1))
+	}
+	if Key("1")+Key("2:3") == Key("1:2")+Key("3") {
+		t.Errorf("Key(): concatenated keys collided")
+	}
+}
+
+func TestMultiKey(t *testing.T) {
+	if MultiKey([]interface{}{1, "c"}) == MultiKey([]interface{}{"1", "c"}) {
+		t.Errorf("MultiKey(): values of different types returned the same key")
+	}
+	if MultiKey([]interface{}{"a | b", "c"}) == MultiKey([]interface{}{"a", "b | c"}) {
+		t.Errorf("MultiKey(): distinct combinations of values returned the same key")
+	}
+}
+
+func TestCommonDataType(t *testing.T) {
+	tests := []struct {
+		name      string
+		dataTypes []options.DataType
+		want      options.DataType
+	}{
+		{"none", nil, options.None},
+		{"same", []options.DataType{options.String, options.String}, options.String},
+		{"numeric", []options.DataType{options.Int64, options.Float64}, options.Float64},
+		{"ignores none", []options.DataType{options.None, options.Int64}, options.Int64},
+		{"mixed", []options.DataType{options.Int64, options.String}, options.Interface},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommonDataType(tt.dataTypes...); got != tt.want {
+				t.Errorf("CommonDataType(): got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScalarSliceFactory(t *testing.T) {
+	tests := []struct {
+		name string
+		data []interface{}
+		want Container
+	}{
+		{"int", []interface{}{1, nil},
+			Container{&int64Values{int64Value{1, false}, int64Value{0, true}}, options.Int64}},
+		{"numeric", []interface{}{1, 1.5},
+			Container{&float64Values{float64Value{1, false}, float64Value{1.5, false}}, options.Float64}},
+		{"mixed", []interface{}{1, "foo"},
+			Container{&interfaceValues{interfaceValue{1, false}, interfaceValue{"foo", false}}, options.Interface}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScalarSliceFactory(tt.data)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScalarSliceFactory(): got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainer_Explode(t *testing.T) {
+	container := Container{&interfaceValues{
+		interfaceValue{[]interface{}{1, 2}, false}, interfaceValue{[]int{}, false},
+		interfaceValue{nil, true}, interfaceValue{3, false}}, options.Interface}
+	got, gotPositions := container.Explode()
+	want := Container{&int64Values{
+		int64Value{1, false}, int64Value{2, false}, int64Value{0, true}, int64Value{0, true}, int64Value{3, false}}, options.Int64}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Container.Explode(): got %v, want %v", got, want)
+	}
+	wantPositions := []int{0, 0, 1, 2, 3}
+	if !reflect.DeepEqual(gotPositions, wantPositions) {
+		t.Errorf("Container.Explode(): got positions %v, want %v", gotPositions, wantPositions)
+	}
+}
+
+func TestContainer_Accumulate(t *testing.T) {
+	container := MustCreateValuesFromInterface([]float64{1, 2, math.NaN(), 4, -1})
+	groups := [][]int{{0, 2, 3}, {1, 4}}
+	tests := []struct {
+		op      string
+		want    string
+		wantErr bool
+	}{
+		{"sum", "[1 2 NaN 5 1]", false},
+		{"prod", "[1 2 NaN 4 -2]", false},
+		{"max", "[1 2 NaN 4 2]", false},
+		{"min", "[1 2 NaN 1 -1]", false},
+		{"fail: unsupported op", "[]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			got, err := container.Accumulate(groups, tt.op)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Container.Accumulate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.DataType != options.Float64 || fmt.Sprint(got.Values.Vals()) != tt.want {
+				t.Errorf("Container.Accumulate(): got %v, want %v", got.Values.Vals(), tt.want)
+			}
+		})
+	}
+	if _, err := MustCreateValuesFromInterface([]string{"foo"}).Accumulate([][]int{{0}}, "sum"); err == nil {
+		t.Errorf("Container.Accumulate() on string values returned nil error, want error")
+	}
+
+	ints := ScalarSliceFactory([]interface{}{int64(3), nil, int64(1), int64(4)})
+	intTests := []struct {
+		op           string
+		want         string
+		wantDataType options.DataType
+	}{
+		{"sum", "[3 <nil> 4 8]", options.Int64},
+		{"max", "[3 <nil> 3 4]", options.Int64},
+		{"min", "[3 <nil> 1 1]", options.Int64},
+		{"prod", "[3 NaN 3 12]", options.Float64},
+	}
+	overflow := MustCreateValuesFromInterface([]int64{math.MaxInt64, 1, -1, 5})
+	if got, _ := overflow.Accumulate([][]int{{0, 1, 2}, {3}}, "sum"); got.Values.Null(0) || !got.Values.Null(1) || !got.Values.Null(2) ||
+		got.Values.Null(3) || got.Values.Value(3) != int64(5) {
+		t.Errorf("Container.Accumulate() on int64 overflow: got %v, want [%v NaN NaN 5]", got.Values.Vals(), int64(math.MaxInt64))
+	}
+	for _, tt := range intTests {
+		t.Run("int64 "+tt.op, func(t *testing.T) {
+			got, err := ints.Accumulate([][]int{{0, 1, 2, 3}}, tt.op)
+			if err != nil {
+				t.Errorf("Container.Accumulate() error = %v, want nil", err)
+			}
+			vals := make([]interface{}, got.Values.Len())
+			for i := range vals {
+				if got.DataType == options.Float64 || !got.Values.Null(i) {
+					vals[i] = got.Values.Value(i)
+				}
+			}
+			if got.DataType != tt.wantDataType || fmt.Sprint(vals) != tt.want {
+				t.Errorf("Container.Accumulate(): got %v (%v), want %v (%v)", vals, got.DataType, tt.want, tt.wantDataType)
+			}
+		})
+	}
+}
+
+func TestContainer_Rank(t *testing.T) {
+	container := MustCreateValuesFromInterface([]float64{3, 1, 3, math.NaN(), 2, 5, 5})
+	groups := [][]int{{0, 1, 2, 3, 4}, {5, 6}}
+	tests := []struct {
+		method  string
+		want    string
+		wantErr bool
+	}{
+		{"", "[3.5 1 3.5 NaN 2 1.5 1.5]", false},
+		{"average", "[3.5 1 3.5 NaN 2 1.5 1.5]", false},
+		{"min", "[3 1 3 NaN 2 1 1]", false},
+		{"max", "[4 1 4 NaN 2 2 2]", false},
+		{"first", "[3 1 4 NaN 2 1 2]", false},
+		{"dense", "[3 1 3 NaN 2 1 1]", false},
+		{"fail: unsupported method", "[]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			got, err := container.Rank(groups, tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Container.Rank() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fmt.Sprint(got.Values.Vals()) != tt.want {
+				t.Errorf("Container.Rank(): got %v, want %v", got.Values.Vals(), tt.want)
+			}
+		})
+	}
+	tm := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	datetimes := MustCreateValuesFromInterface([]time.Time{tm.Add(time.Hour), tm, tm.Add(time.Minute)})
+	if got, _ := datetimes.Rank([][]int{{0, 1, 2}}, "first"); fmt.Sprint(got.Values.Vals()) != "[3 1 2]" {
+		t.Errorf("Container.Rank() on datetime values: got %v, want [3 1 2]", got.Values.Vals())
+	}
+}
+
 func TestValues_Transpose(t *testing.T) {
 	type args struct {
 		data [][]interface{}
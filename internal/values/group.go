@@ -0,0 +1,177 @@
+package values
+
+import (
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/ptiger10/pd/options"
+)
+
+// Factorize returns the code of every label in vals, numbered in order of first appearance, and the number of codes.
+// Labels share a code if and only if they share a Key, so null float labels share a code,
+// and labels of different types never do.
+func Factorize(vals Values) ([]int, int) {
+	codes := make([]int, vals.Len())
+	switch data := vals.Vals().(type) {
+	case []float64:
+		seen := make(map[uint64]int)
+		nullCode := -1
+		for i, d := range data {
+			if math.IsNaN(d) {
+				if nullCode == -1 {
+					nullCode = len(seen)
+					// reserve the code with a key that no float64 label can have after the NaN check
+					seen[math.Float64bits(math.NaN())] = nullCode
+				}
+				codes[i] = nullCode
+				continue
+			}
+			code, ok := seen[math.Float64bits(d)]
+			if !ok {
+				code = len(seen)
+				seen[math.Float64bits(d)] = code
+			}
+			codes[i] = code
+		}
+		return codes, len(seen)
+	case []int64:
+		seen := make(map[int64]int)
+		for i, d := range data {
+			code, ok := seen[d]
+			if !ok {
+				code = len(seen)
+				seen[d] = code
+			}
+			codes[i] = code
+		}
+		return codes, len(seen)
+	case []string:
+		seen := make(map[string]int)
+		for i, d := range data {
+			code, ok := seen[d]
+			if !ok {
+				code = len(seen)
+				seen[d] = code
+			}
+			codes[i] = code
+		}
+		return codes, len(seen)
+	case []bool:
+		seen := [2]int{-1, -1}
+		var n int
+		for i, d := range data {
+			var b int
+			if d {
+				b = 1
+			}
+			if seen[b] == -1 {
+				seen[b] = n
+				n++
+			}
+			codes[i] = seen[b]
+		}
+		return codes, n
+	default:
+		seen := make(map[string]int)
+		for i := range codes {
+			key := Key(vals.Value(i))
+			code, ok := seen[key]
+			if !ok {
+				code = len(seen)
+				seen[key] = code
+			}
+			codes[i] = code
+		}
+		return codes, len(seen)
+	}
+}
+
+// CombineCodes returns the code of every pair of codes in a and b, numbered in order of first appearance, and the number of codes.
+// nb is the number of codes in b.
+func CombineCodes(a []int, na int, b []int, nb int) ([]int, int) {
+	codes := make([]int, len(a))
+	var n int
+	// a lookup table is faster than a map if it is not much larger than the codes
+	if na*nb <= 4*len(a)+16 {
+		table := make([]int, na*nb)
+		for k := range table {
+			table[k] = -1
+		}
+		for i := range a {
+			pair := a[i]*nb + b[i]
+			if table[pair] == -1 {
+				table[pair] = n
+				n++
+			}
+			codes[i] = table[pair]
+		}
+		return codes, n
+	}
+	seen := make(map[[2]int]int)
+	for i := range a {
+		pair := [2]int{a[i], b[i]}
+		code, ok := seen[pair]
+		if !ok {
+			code = len(seen)
+			seen[pair] = code
+		}
+		codes[i] = code
+	}
+	return codes, len(seen)
+}
+
+// GroupPositions returns the positions of every code in codes, in code order.
+// All positions share one backing array, so each slice of positions has no spare capacity.
+func GroupPositions(codes []int, numCodes int) [][]int {
+	offsets := make([]int, numCodes+1)
+	for _, code := range codes {
+		offsets[code+1]++
+	}
+	for k := 1; k <= numCodes; k++ {
+		offsets[k] += offsets[k-1]
+	}
+	positions := make([]int, len(codes))
+	next := make([]int, numCodes)
+	copy(next, offsets)
+	for i, code := range codes {
+		positions[next[code]] = i
+		next[code]++
+	}
+	ret := make([][]int, numCodes)
+	for k := range ret {
+		ret[k] = positions[offsets[k]:offsets[k+1]:offsets[k+1]]
+	}
+	return ret
+}
+
+// ForEachGroup calls fn with every group position from 0 to n-1.
+// If options.GetAsync() is true, the positions are split into contiguous ranges among at most runtime.NumCPU() goroutines.
+func ForEachGroup(n int, fn func(k int)) {
+	workers := runtime.NumCPU()
+	if workers > n {
+		workers = n
+	}
+	if !options.GetAsync() || workers <= 1 {
+		for k := 0; k < n; k++ {
+			fn(k)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	size := (n + workers - 1) / workers
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			for k := start; k < end; k++ {
+				fn(k)
+			}
+			wg.Done()
+		}(start, end)
+	}
+	wg.Wait()
+}
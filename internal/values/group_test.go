@@ -0,0 +1,78 @@
+package values
+
+import (
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ptiger10/pd/options"
+)
+
+func TestFactorize(t *testing.T) {
+	tm := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		input     interface{}
+		wantCodes []int
+		wantN     int
+	}{
+		{"float64 with nulls", []float64{1, math.NaN(), 1, 2, math.NaN()}, []int{0, 1, 0, 2, 1}, 3},
+		{"int64", []int64{3, 1, 3}, []int{0, 1, 0}, 2},
+		{"string", []string{"b", "a", "b", "c"}, []int{0, 1, 0, 2}, 3},
+		{"bool", []bool{false, true, false}, []int{0, 1, 0}, 2},
+		{"datetime", []time.Time{tm, tm.Add(time.Hour), tm}, []int{0, 1, 0}, 2},
+		{"interface of mixed types", []interface{}{1, "1", 1, int64(1)}, []int{0, 1, 0, 2}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCodes, gotN := Factorize(MustCreateValuesFromInterface(tt.input).Values)
+			if !reflect.DeepEqual(gotCodes, tt.wantCodes) || gotN != tt.wantN {
+				t.Errorf("Factorize() = %v, %v, want %v, %v", gotCodes, gotN, tt.wantCodes, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestCombineCodes(t *testing.T) {
+	a, b := []int{0, 0, 1, 0}, []int{0, 1, 0, 0}
+	wantCodes, wantN := []int{0, 1, 2, 0}, 3
+	gotCodes, gotN := CombineCodes(a, 2, b, 2)
+	if !reflect.DeepEqual(gotCodes, wantCodes) || gotN != wantN {
+		t.Errorf("CombineCodes() with lookup table = %v, %v, want %v, %v", gotCodes, gotN, wantCodes, wantN)
+	}
+	gotCodes, gotN = CombineCodes(a, 1000, b, 1000)
+	if !reflect.DeepEqual(gotCodes, wantCodes) || gotN != wantN {
+		t.Errorf("CombineCodes() with map = %v, %v, want %v, %v", gotCodes, gotN, wantCodes, wantN)
+	}
+}
+
+func TestGroupPositions(t *testing.T) {
+	got := GroupPositions([]int{0, 1, 0, 2, 1}, 3)
+	want := [][]int{{0, 2}, {1, 4}, {3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupPositions() = %v, want %v", got, want)
+	}
+}
+
+func TestForEachGroup(t *testing.T) {
+	for _, async := range []bool{true, false} {
+		options.SetAsync(async)
+		for _, n := range []int{0, 1, 5, 1000} {
+			var mu sync.Mutex
+			calls := make([]int, n)
+			ForEachGroup(n, func(k int) {
+				mu.Lock()
+				calls[k]++
+				mu.Unlock()
+			})
+			for k, c := range calls {
+				if c != 1 {
+					t.Errorf("ForEachGroup(%d) with async %v called fn(%d) %d times, want 1", n, async, k, c)
+				}
+			}
+		}
+	}
+	options.RestoreDefaults()
+}
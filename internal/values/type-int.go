@@ -24,6 +24,64 @@ func (vals *int64Values) Less(i, j int) bool {
 
 // [END Constructor Functions]
 
+// [START Checked Arithmetic]
+
+// AddInt64 returns a + b, or false if the sum overflows int64.
+func AddInt64(a, b int64) (int64, bool) {
+	c := a + b
+	if (c > a) != (b > 0) {
+		return 0, false
+	}
+	return c, true
+}
+
+// SubInt64 returns a - b, or false if the difference overflows int64.
+func SubInt64(a, b int64) (int64, bool) {
+	c := a - b
+	if (c < a) != (b > 0) {
+		return 0, false
+	}
+	return c, true
+}
+
+// MulInt64 returns a * b, or false if the product overflows int64.
+func MulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if (c < 0) != ((a < 0) != (b < 0)) || c/b != a {
+		return 0, false
+	}
+	return c, true
+}
+
+// PowInt64 raises a to the power of b by squaring, and returns false if b is negative or the result overflows int64.
+func PowInt64(a, b int64) (int64, bool) {
+	if b < 0 {
+		return 0, false
+	}
+	ret := int64(1)
+	var ok bool
+	for b > 0 {
+		if b&1 == 1 {
+			if ret, ok = MulInt64(ret, a); !ok {
+				return 0, false
+			}
+		}
+		b >>= 1
+		// only square the base if a higher bit needs it, so that an unused square cannot overflow
+		if b > 0 {
+			if a, ok = MulInt64(a, a); !ok {
+				return 0, false
+			}
+		}
+	}
+	return ret, true
+}
+
+// [END Checked Arithmetic]
+
 // [START Converters]
 
 // toFloat converts int64Value to float64Value
@@ -4,6 +4,7 @@ package values
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ptiger10/pd/options"
 )
@@ -62,3 +63,27 @@ func Convert(currentVals Values, dataType options.DataType) (Values, error) {
 	}
 	return vals, nil
 }
+
+// Key returns a string that identifies val by both its type and its value, for use as a hash key.
+// Values of different types never share a Key (e.g., int64(1) and "1"),
+// and Keys may be concatenated without collision because the value is prefixed with its length.
+func Key(val interface{}) string {
+	var s string
+	switch v := val.(type) {
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	default:
+		s = fmt.Sprint(v)
+	}
+	return fmt.Sprintf("%T:%d:%s", val, len(s), s)
+}
+
+// MultiKey returns a string that identifies vals by the type and value of each element, in order, for use as a hash key.
+// Distinct combinations of values never share a MultiKey.
+func MultiKey(vals []interface{}) string {
+	var key string
+	for _, val := range vals {
+		key += Key(val)
+	}
+	return key
+}
@@ -225,6 +225,74 @@ func ReadCSV(path string, config ...ReadOptions) (*dataframe.DataFrame, error) {
 	return df, nil
 }
 
+// Crosstab returns a frequency table of the labels in rows and cols, or an aggregation of config.Values by those labels.
+// See dataframe.Crosstab for details.
+func Crosstab(rows, cols *series.Series, config ...CrosstabOptions) (*dataframe.DataFrame, error) {
+	tmp := CrosstabOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return dataframe.MustNew(nil), fmt.Errorf("pd.Crosstab(): can supply at most one CrosstabOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	df, err := dataframe.Crosstab(rows, cols, dataframe.CrosstabOptions{
+		Values: tmp.Values, AggFunc: tmp.AggFunc,
+		Normalize: tmp.Normalize, Margins: tmp.Margins, DropNA: tmp.DropNA,
+	})
+	if err != nil {
+		return dataframe.MustNew(nil), fmt.Errorf("pd.Crosstab(): %v", err)
+	}
+	return df, nil
+}
+
+// GetDummies returns a copy of df in which the columns named cols are replaced by indicator columns, one per category.
+// See dataframe.GetDummies for details.
+func GetDummies(df *dataframe.DataFrame, cols []string, config ...DummyOptions) (*dataframe.DataFrame, error) {
+	tmp := DummyOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return dataframe.MustNew(nil), fmt.Errorf("pd.GetDummies(): can supply at most one DummyOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	ret, err := df.GetDummies(cols, dataframe.DummyOptions{
+		Prefix: tmp.Prefix, PrefixSep: tmp.PrefixSep,
+		DropFirst: tmp.DropFirst, DummyNA: tmp.DummyNA,
+		DataType: tmp.DataType, MultiLevel: tmp.MultiLevel,
+	})
+	if err != nil {
+		return dataframe.MustNew(nil), fmt.Errorf("pd.GetDummies(): %v", err)
+	}
+	return ret, nil
+}
+
+// FromDummies returns a copy of df in which each group of indicator columns is replaced by a single column of categories.
+// See dataframe.FromDummies for details.
+func FromDummies(df *dataframe.DataFrame, config ...FromDummiesOptions) (*dataframe.DataFrame, error) {
+	tmp := FromDummiesOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return dataframe.MustNew(nil), fmt.Errorf("pd.FromDummies(): can supply at most one FromDummiesOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	ret, err := df.FromDummies(dataframe.FromDummiesOptions{PrefixSep: tmp.PrefixSep, DefaultCategory: tmp.DefaultCategory})
+	if err != nil {
+		return dataframe.MustNew(nil), fmt.Errorf("pd.FromDummies(): %v", err)
+	}
+	return ret, nil
+}
+
+// WideToLong reshapes the columns named stub + sep + suffix into rows, with the suffixes in a new column named j.
+// See dataframe.WideToLong for details.
+func WideToLong(df *dataframe.DataFrame, stubnames []string, i []string, j string, sep string, suffixRegex string) (*dataframe.DataFrame, error) {
+	ret, err := df.WideToLong(stubnames, i, j, sep, suffixRegex)
+	if err != nil {
+		return dataframe.MustNew(nil), fmt.Errorf("pd.WideToLong(): %v", err)
+	}
+	return ret, nil
+}
+
 // Config customizes the construction of either a DataFrame or Series.
 type Config struct {
 	Name            string
@@ -252,3 +320,28 @@ type ReadOptions struct {
 	Rename          map[string]string
 	Delimiter       rune
 }
+
+// CrosstabOptions customizes Crosstab. See dataframe.CrosstabOptions for details.
+type CrosstabOptions struct {
+	Values    *series.Series
+	AggFunc   series.Agg
+	Normalize string
+	Margins   bool
+	DropNA    bool
+}
+
+// DummyOptions customizes GetDummies. See dataframe.DummyOptions for details.
+type DummyOptions struct {
+	Prefix     map[string]string
+	PrefixSep  string
+	DropFirst  bool
+	DummyNA    bool
+	DataType   options.DataType
+	MultiLevel bool
+}
+
+// FromDummiesOptions customizes FromDummies. See dataframe.FromDummiesOptions for details.
+type FromDummiesOptions struct {
+	PrefixSep       string
+	DefaultCategory map[string]string
+}
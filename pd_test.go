@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ptiger10/pd/dataframe"
+	"github.com/ptiger10/pd/options"
 	"github.com/ptiger10/pd/series"
 )
 
@@ -282,3 +283,64 @@ func TestInterface(t *testing.T) {
 		})
 	}
 }
+
+func TestCrosstab(t *testing.T) {
+	rows := series.MustNew([]string{"a", "a", "b"}, series.Config{Name: "foo"})
+	cols := series.MustNew([]string{"x", "y", "x"}, series.Config{Name: "bar"})
+	got, err := Crosstab(rows, cols, CrosstabOptions{Margins: true})
+	if err != nil {
+		t.Errorf("Crosstab() error: %v", err)
+	}
+	want := dataframe.MustNew([]interface{}{[]int{1, 1, 2}, []int{1, 0, 1}, []int{2, 1, 3}},
+		dataframe.Config{Index: []string{"a", "b", "All"}, IndexName: "foo", Col: []string{"x", "y", "All"}, ColName: "bar"})
+	if !dataframe.Equal(got, want) {
+		t.Errorf("Crosstab() got %v, want %v", got, want)
+	}
+	if _, err := Crosstab(rows, cols, CrosstabOptions{}, CrosstabOptions{}); err == nil {
+		t.Errorf("Crosstab() returned nil error for multiple CrosstabOptions")
+	}
+	if _, err := Crosstab(rows, cols, CrosstabOptions{Normalize: "foo"}); err == nil {
+		t.Errorf("Crosstab() returned nil error for invalid Normalize")
+	}
+}
+
+func TestGetDummies(t *testing.T) {
+	df := dataframe.MustNew([]interface{}{[]string{"b", "a"}}, dataframe.Config{Col: []string{"foo"}})
+	got, err := GetDummies(df, []string{"foo"}, DummyOptions{DataType: options.Int64})
+	if err != nil {
+		t.Errorf("GetDummies() error: %v", err)
+	}
+	want := dataframe.MustNew([]interface{}{[]int64{0, 1}, []int64{1, 0}}, dataframe.Config{Col: []string{"foo_a", "foo_b"}})
+	if !dataframe.Equal(got, want) {
+		t.Errorf("GetDummies() got %v, want %v", got, want)
+	}
+	if _, err := GetDummies(df, nil, DummyOptions{}, DummyOptions{}); err == nil {
+		t.Errorf("GetDummies() returned nil error for multiple DummyOptions")
+	}
+
+	got, err = FromDummies(got, FromDummiesOptions{})
+	if err != nil {
+		t.Errorf("FromDummies() error: %v", err)
+	}
+	if !dataframe.Equal(got, df) {
+		t.Errorf("FromDummies() got %v, want %v", got, df)
+	}
+	if _, err := FromDummies(got, FromDummiesOptions{}, FromDummiesOptions{}); err == nil {
+		t.Errorf("FromDummies() returned nil error for multiple FromDummiesOptions")
+	}
+}
+
+func TestWideToLong(t *testing.T) {
+	df := dataframe.MustNew([]interface{}{[]string{"a"}, []int{1}, []int{2}}, dataframe.Config{Col: []string{"id", "score_2019", "score_2020"}})
+	got, err := WideToLong(df, []string{"score"}, []string{"id"}, "year", "_", `\d+`)
+	if err != nil {
+		t.Errorf("WideToLong() error: %v", err)
+	}
+	want := dataframe.MustNew([]interface{}{[]string{"a", "a"}, []int{2019, 2020}, []int{1, 2}}, dataframe.Config{Col: []string{"id", "year", "score"}})
+	if !dataframe.Equal(got, want) {
+		t.Errorf("WideToLong() got %v, want %v", got, want)
+	}
+	if _, err := WideToLong(df, []string{"weight"}, []string{"id"}, "year", "_", ""); err == nil {
+		t.Errorf("WideToLong() returned nil error for unmatched stub")
+	}
+}
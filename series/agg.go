@@ -0,0 +1,158 @@
+package series
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ptiger10/pd/options"
+)
+
+// An Agg is a named function that reduces a Series to a single value, such as a sum or a count.
+// A nil result is null.
+type Agg struct {
+	name string
+	fn   func(*Series) interface{}
+}
+
+// NewAgg returns a named aggregation that reduces a Series with fn.
+// fn may return a value of any supported type, or nil for null.
+func NewAgg(name string, fn func(*Series) interface{}) Agg {
+	return Agg{name: name, fn: fn}
+}
+
+// Name returns the name of the aggregation.
+func (agg Agg) Name() string {
+	return agg.name
+}
+
+// Eval reduces s to a single value, or nil if the result is null.
+func (agg Agg) Eval(s *Series) interface{} {
+	if agg.fn == nil {
+		return nil
+	}
+	return agg.fn(s)
+}
+
+// floatAgg wraps a float64 reduction so that NaN results are null.
+func floatAgg(fn func(*Series) float64) func(*Series) interface{} {
+	return func(s *Series) interface{} {
+		v := fn(s)
+		if math.IsNaN(v) {
+			return nil
+		}
+		return v
+	}
+}
+
+// timeOrFloatAgg wraps a time.Time reduction for DateTime Series and a float64 reduction for other Series,
+// so that zero times and NaN results are null.
+func timeOrFloatAgg(timeFn func(*Series) time.Time, fn func(*Series) float64) func(*Series) interface{} {
+	return func(s *Series) interface{} {
+		if s.datatype != options.DateTime {
+			return floatAgg(fn)(s)
+		}
+		v := timeFn(s)
+		if v.IsZero() {
+			return nil
+		}
+		return v
+	}
+}
+
+// Built-in aggregations. Numeric aggregations return float64 and are null if inapplicable,
+// except that AggMin and AggMax return the earliest and latest time.Time of a DateTime Series.
+// AggCount, AggSize and AggNUnique return the number of non-null values, all values and unique non-null values as int64.
+// AggFirst, AggLast and AggMode return the first, last and most common non-null values with their original type.
+var (
+	AggSum     = Agg{"sum", floatAgg((*Series).Sum)}
+	AggMean    = Agg{"mean", floatAgg((*Series).Mean)}
+	AggMedian  = Agg{"median", floatAgg((*Series).Median)}
+	AggMin     = Agg{"min", timeOrFloatAgg((*Series).Earliest, (*Series).Min)}
+	AggMax     = Agg{"max", timeOrFloatAgg((*Series).Latest, (*Series).Max)}
+	AggStd     = Agg{"std", floatAgg((*Series).Std)}
+	AggVar     = Agg{"var", floatAgg((*Series).Var)}
+	AggProd    = Agg{"prod", floatAgg((*Series).Prod)}
+	AggCount   = Agg{"count", func(s *Series) interface{} { return int64(s.Count()) }}
+	AggSize    = Agg{"size", func(s *Series) interface{} { return int64(s.Len()) }}
+	AggNUnique = Agg{"nunique", func(s *Series) interface{} { return int64(s.NUnique()) }}
+	AggMode    = Agg{"mode", (*Series).Mode}
+	AggFirst   = Agg{"first", func(s *Series) interface{} {
+		valid := s.valid()
+		if len(valid) == 0 {
+			return nil
+		}
+		return s.values.Value(valid[0])
+	}}
+	AggLast = Agg{"last", func(s *Series) interface{} {
+		valid := s.valid()
+		if len(valid) == 0 {
+			return nil
+		}
+		return s.values.Value(valid[len(valid)-1])
+	}}
+)
+
+// AggQuantile returns an aggregation named "quantile_" + q that returns the quantile q as float64 (see Series.Quantile).
+func AggQuantile(q float64) Agg {
+	return Agg{fmt.Sprintf("quantile_%v", q), floatAgg(func(s *Series) float64 { return s.Quantile(q) })}
+}
+
+// AggNth returns an aggregation named "nth_" + n that returns the value at position n with its original type,
+// counting from the end if n is negative. The result is null if the value is null or n is out of range.
+func AggNth(n int) Agg {
+	return Agg{fmt.Sprintf("nth_%d", n), func(s *Series) interface{} {
+		i := n
+		if i < 0 {
+			i += s.Len()
+		}
+		if i < 0 || i >= s.Len() || s.values.Null(i) {
+			return nil
+		}
+		return s.values.Value(i)
+	}}
+}
+
+var aggRegistry = struct {
+	sync.RWMutex
+	aggs map[string]Agg
+}{aggs: map[string]Agg{}}
+
+func init() {
+	for _, agg := range []Agg{
+		AggSum, AggMean, AggMedian, AggMin, AggMax, AggStd, AggVar, AggProd,
+		AggCount, AggSize, AggNUnique, AggMode, AggFirst, AggLast,
+	} {
+		aggRegistry.aggs[agg.name] = agg
+	}
+}
+
+// RegisterAgg registers agg under its name, so that it may be looked up with LookupAgg
+// or used by name wherever aggregation names are accepted (e.g., DataFrame.Pivot).
+// Registering a name again replaces the earlier aggregation. Safe for concurrent use.
+func RegisterAgg(agg Agg) error {
+	if agg.name == "" {
+		return fmt.Errorf("series.RegisterAgg(): aggregation must have a name")
+	}
+	if agg.fn == nil {
+		return fmt.Errorf("series.RegisterAgg(): aggregation %q must have a function", agg.name)
+	}
+	aggRegistry.Lock()
+	aggRegistry.aggs[agg.name] = agg
+	aggRegistry.Unlock()
+	return nil
+}
+
+// LookupAgg returns the aggregation registered under name.
+// The built-in aggregations are registered as sum, mean, median, min, max, std, var, prod,
+// count, size, nunique, mode, first and last.
+func LookupAgg(name string) (Agg, error) {
+	aggRegistry.RLock()
+	agg, ok := aggRegistry.aggs[name]
+	aggRegistry.RUnlock()
+	if !ok {
+		return Agg{}, fmt.Errorf("series.LookupAgg(): no aggregation registered as %q", name)
+	}
+	return agg, nil
+}
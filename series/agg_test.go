@@ -0,0 +1,72 @@
+package series
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ptiger10/pd/options"
+)
+
+func TestAgg(t *testing.T) {
+	ints := MustNew([]interface{}{1, "", 3, 3}, Config{DataType: options.Int64})
+	tests := []struct {
+		name  string
+		agg   Agg
+		input *Series
+		want  interface{}
+	}{
+		{"sum", AggSum, ints, 7.0},
+		{"mean", AggMean, ints, 7.0 / 3},
+		{"median", AggMedian, ints, 3.0},
+		{"min", AggMin, ints, 1.0},
+		{"max", AggMax, ints, 3.0},
+		{"min: datetime", AggMin, MustNew([]time.Time{time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}),
+			time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"max: datetime", AggMax, MustNew([]time.Time{time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC), {}}),
+			time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"null: max datetime", AggMax, MustNew([]time.Time{{}}), nil},
+		{"std", AggStd, MustNew([]float64{1, 3}), 1.0},
+		{"count", AggCount, ints, int64(3)},
+		{"nunique", AggNUnique, ints, int64(2)},
+		{"first", AggFirst, MustNew([]string{"", "foo", "bar"}), "foo"},
+		{"last", AggLast, MustNew([]string{"foo", "bar", ""}), "bar"},
+		{"null: first", AggFirst, MustNew([]string{""}), nil},
+		{"null: inapplicable", AggMean, MustNew([]string{"foo"}), nil},
+		{"null: zero value", Agg{}, ints, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.agg.Eval(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Agg.Eval() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterAgg(t *testing.T) {
+	agg := NewAgg("test_len", func(s *Series) interface{} { return s.Len() })
+	if err := RegisterAgg(agg); err != nil {
+		t.Errorf("RegisterAgg() error: %v", err)
+	}
+	got, err := LookupAgg("test_len")
+	if err != nil {
+		t.Errorf("LookupAgg() error: %v", err)
+	}
+	if got.Name() != "test_len" || got.Eval(MustNew([]int{1, 2})) != 2 {
+		t.Errorf("LookupAgg() returned %v, want registered aggregation", got.Name())
+	}
+	if got, _ := LookupAgg("sum"); got.Name() != "sum" {
+		t.Errorf("LookupAgg() returned %v for built-in, want sum", got.Name())
+	}
+	if _, err := LookupAgg("unregistered"); err == nil {
+		t.Errorf("LookupAgg() returned nil error for unregistered name")
+	}
+	if err := RegisterAgg(NewAgg("", agg.fn)); err == nil {
+		t.Errorf("RegisterAgg() returned nil error for unnamed aggregation")
+	}
+	if err := RegisterAgg(NewAgg("foo", nil)); err == nil {
+		t.Errorf("RegisterAgg() returned nil error for nil function")
+	}
+}
@@ -0,0 +1,328 @@
+package series
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/ptiger10/pd/internal/index"
+	"github.com/ptiger10/pd/internal/values"
+	"github.com/ptiger10/pd/options"
+)
+
+// An arithmetic operation is applied element-wise to float64 values, or to int64 values if both operands are int64 or bool.
+// If the int function returns false (e.g., on int64 overflow), the result is null.
+type arithmetic struct {
+	name      string
+	floatFunc func(a, b float64) float64
+	intFunc   func(a, b int64) (int64, bool)
+}
+
+var (
+	addition = arithmetic{"Add",
+		func(a, b float64) float64 { return a + b },
+		values.AddInt64}
+	subtraction = arithmetic{"Sub",
+		func(a, b float64) float64 { return a - b },
+		values.SubInt64}
+	multiplication = arithmetic{"Mul",
+		func(a, b float64) float64 { return a * b },
+		values.MulInt64}
+	// division always returns float64
+	division = arithmetic{"Div",
+		func(a, b float64) float64 { return a / b },
+		nil}
+	modulo = arithmetic{"Mod",
+		math.Mod,
+		func(a, b int64) (int64, bool) {
+			if b == 0 {
+				return 0, false
+			}
+			return a % b, true
+		}}
+	power = arithmetic{"Pow",
+		math.Pow,
+		values.PowInt64}
+)
+
+// Add adds the values in other to the values in s with the same index labels and returns a new Series.
+//
+// Values are aligned on their labels across every index level, and the result contains the union of labels in s and other:
+// labels in s first, in their original order, followed by labels only in other.
+// Duplicate labels are paired in their order of appearance.
+//
+// A result is null if either value is null or missing, unless fillValue is not nil,
+// in which case fillValue replaces the value on one side (but not both).
+//
+// Both Series must be float64, int64 or bool (treated as 0 or 1).
+// The result is int64 if both Series are int64 or bool (and fillValue is not a float), and float64 otherwise.
+// An int64 result that overflows is null, rather than wrapping around.
+func (s *Series) Add(other *Series, fillValue interface{}) (*Series, error) {
+	return s.align(addition, other, fillValue)
+}
+
+// Sub subtracts the values in other from the values in s with the same index labels and returns a new Series.
+// Alignment, nulls and DataTypes are handled as in Add.
+func (s *Series) Sub(other *Series, fillValue interface{}) (*Series, error) {
+	return s.align(subtraction, other, fillValue)
+}
+
+// Mul multiplies the values in s by the values in other with the same index labels and returns a new Series.
+// Alignment, nulls and DataTypes are handled as in Add.
+func (s *Series) Mul(other *Series, fillValue interface{}) (*Series, error) {
+	return s.align(multiplication, other, fillValue)
+}
+
+// Div divides the values in s by the values in other with the same index labels and returns a new float64 Series.
+// Alignment and nulls are handled as in Add.
+func (s *Series) Div(other *Series, fillValue interface{}) (*Series, error) {
+	return s.align(division, other, fillValue)
+}
+
+// Mod returns the remainder of dividing the values in s by the values in other with the same index labels, as a new Series.
+// As with Go's % operator, the result has the sign of the dividend. Integer modulo by zero is null.
+// Alignment, nulls and DataTypes are handled as in Add.
+func (s *Series) Mod(other *Series, fillValue interface{}) (*Series, error) {
+	return s.align(modulo, other, fillValue)
+}
+
+// Pow raises the values in s to the power of the values in other with the same index labels and returns a new Series.
+// Integers raised to negative integer powers, or to powers that overflow int64, are null.
+// Alignment, nulls and DataTypes are handled as in Add.
+func (s *Series) Pow(other *Series, fillValue interface{}) (*Series, error) {
+	return s.align(power, other, fillValue)
+}
+
+// AddScalar adds value to every value in s and returns a new Series.
+func (s *Series) AddScalar(value interface{}) (*Series, error) {
+	return s.broadcast(addition, value)
+}
+
+// SubScalar subtracts value from every value in s and returns a new Series.
+func (s *Series) SubScalar(value interface{}) (*Series, error) {
+	return s.broadcast(subtraction, value)
+}
+
+// MulScalar multiplies every value in s by value and returns a new Series.
+func (s *Series) MulScalar(value interface{}) (*Series, error) {
+	return s.broadcast(multiplication, value)
+}
+
+// DivScalar divides every value in s by value and returns a new float64 Series.
+func (s *Series) DivScalar(value interface{}) (*Series, error) {
+	return s.broadcast(division, value)
+}
+
+// ModScalar returns the remainder of dividing every value in s by value, as a new Series.
+func (s *Series) ModScalar(value interface{}) (*Series, error) {
+	return s.broadcast(modulo, value)
+}
+
+// PowScalar raises every value in s to the power of value and returns a new Series.
+func (s *Series) PowScalar(value interface{}) (*Series, error) {
+	return s.broadcast(power, value)
+}
+
+// operand is a numeric Series or scalar, aligned to the positions of the result.
+type operand struct {
+	floats []float64
+	ints   []int64
+	nulls  []bool
+}
+
+// isIntegral returns true for DataTypes that are computed as int64.
+func isIntegral(dataType options.DataType) bool {
+	return dataType == options.Int64 || dataType == options.Bool
+}
+
+func ensureArithmetic(dataType options.DataType) error {
+	switch dataType {
+	case options.Float64, options.Int64, options.Bool:
+		return nil
+	}
+	return fmt.Errorf("unsupported DataType %v (must be float64, int64 or bool)", dataType)
+}
+
+// scalarDataType returns the DataType of a numeric scalar.
+func scalarDataType(value interface{}) (options.DataType, error) {
+	switch value.(type) {
+	case float32, float64,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		bool:
+		// ducks error because every numeric type is supported
+		container, _ := values.ScalarFactory(value)
+		return container.DataType, nil
+	}
+	return options.None, fmt.Errorf("unsupported scalar %v (%T): must be numeric or bool", value, value)
+}
+
+// operand returns the values of s at positions, with -1 treated as null.
+func (s *Series) operand(positions []int) operand {
+	floats := ensureFloatFromNumerics(s.values.ToFloat64().Vals())
+	ints := s.values.ToInt64().Vals().([]int64)
+	ret := operand{
+		floats: make([]float64, len(positions)),
+		ints:   make([]int64, len(positions)),
+		nulls:  make([]bool, len(positions)),
+	}
+	for i, pos := range positions {
+		if pos == -1 || s.values.Null(pos) {
+			ret.nulls[i] = true
+			continue
+		}
+		ret.floats[i] = floats[pos]
+		ret.ints[i] = ints[pos]
+	}
+	return ret
+}
+
+// operandFromScalar repeats value n times.
+func operandFromScalar(value interface{}, n int) operand {
+	// ducks error because value is a supported scalar
+	container, _ := values.ScalarFactory(value)
+	f := container.Values.ToFloat64().Vals().([]float64)[0]
+	i := container.Values.ToInt64().Vals().([]int64)[0]
+	ret := operand{
+		floats: make([]float64, n),
+		ints:   make([]int64, n),
+		nulls:  make([]bool, n),
+	}
+	for k := 0; k < n; k++ {
+		ret.floats[k] = f
+		ret.ints[k] = i
+	}
+	return ret
+}
+
+// fill replaces values that are null on one side only with the fill operand.
+func fill(left, right, fillValue operand) {
+	for i := range left.nulls {
+		if left.nulls[i] && !right.nulls[i] {
+			left.floats[i], left.ints[i], left.nulls[i] = fillValue.floats[i], fillValue.ints[i], false
+		} else if right.nulls[i] && !left.nulls[i] {
+			right.floats[i], right.ints[i], right.nulls[i] = fillValue.floats[i], fillValue.ints[i], false
+		}
+	}
+}
+
+// align aligns s and other on their index labels and applies op.
+func (s *Series) align(op arithmetic, other *Series, fillValue interface{}) (*Series, error) {
+	if err := ensureArithmetic(s.datatype); err != nil {
+		return newEmptySeries(), fmt.Errorf("Series.%v(): s: %v", op.name, err)
+	}
+	if err := ensureArithmetic(other.datatype); err != nil {
+		return newEmptySeries(), fmt.Errorf("Series.%v(): other: %v", op.name, err)
+	}
+	integral := isIntegral(s.datatype) && isIntegral(other.datatype)
+	if fillValue != nil {
+		fillType, err := scalarDataType(fillValue)
+		if err != nil {
+			return newEmptySeries(), fmt.Errorf("Series.%v(): fillValue: %v", op.name, err)
+		}
+		integral = integral && isIntegral(fillType)
+	}
+	idx, leftPositions, rightPositions, err := s.index.Align(other.index)
+	if err != nil {
+		return newEmptySeries(), fmt.Errorf("Series.%v(): %v", op.name, err)
+	}
+	left := s.operand(leftPositions)
+	right := other.operand(rightPositions)
+	if fillValue != nil {
+		fill(left, right, operandFromScalar(fillValue, idx.Len()))
+	}
+	name := s.name
+	if other.name != s.name {
+		name = ""
+	}
+	return calculate(op, left, right, integral, idx, name), nil
+}
+
+// broadcast applies op to every value in s and a scalar value.
+func (s *Series) broadcast(op arithmetic, value interface{}) (*Series, error) {
+	if err := ensureArithmetic(s.datatype); err != nil {
+		return newEmptySeries(), fmt.Errorf("Series.%vScalar(): %v", op.name, err)
+	}
+	dataType, err := scalarDataType(value)
+	if err != nil {
+		return newEmptySeries(), fmt.Errorf("Series.%vScalar(): %v", op.name, err)
+	}
+	left := s.operand(values.MakeIntRange(0, s.Len()))
+	right := operandFromScalar(value, s.Len())
+	integral := isIntegral(s.datatype) && isIntegral(dataType)
+	return calculate(op, left, right, integral, s.index.Copy(), s.name), nil
+}
+
+// calculate applies op to every pair of left and right values, concurrently if options.GetAsync() is true.
+func calculate(op arithmetic, left, right operand, integral bool, idx index.Index, name string) *Series {
+	n := len(left.nulls)
+	if op.intFunc == nil {
+		integral = false
+	}
+	nulls := make([]bool, n)
+	var floats []float64
+	var ints []int64
+	if integral {
+		ints = make([]int64, n)
+	} else {
+		floats = make([]float64, n)
+	}
+	calc := func(start, end int) {
+		for i := start; i < end; i++ {
+			if left.nulls[i] || right.nulls[i] {
+				nulls[i] = true
+				if !integral {
+					floats[i] = math.NaN()
+				}
+				continue
+			}
+			if integral {
+				var ok bool
+				ints[i], ok = op.intFunc(left.ints[i], right.ints[i])
+				nulls[i] = !ok
+			} else {
+				floats[i] = op.floatFunc(left.floats[i], right.floats[i])
+			}
+		}
+	}
+	if !options.GetAsync() {
+		calc(0, n)
+	} else {
+		asyncPartitions(n, calc)
+	}
+
+	var container values.Container
+	if integral {
+		container = values.MustCreateValuesFromInterface(ints)
+		for i := range nulls {
+			if nulls[i] {
+				container.Values.Set(i, "")
+			}
+		}
+	} else {
+		container = values.MustCreateValuesFromInterface(floats)
+	}
+	return FromInternalComponents(container, idx, name)
+}
+
+// asyncPartitions partitions the positions [0, n) in the same manner as asyncMath
+// and calls fn on each partition concurrently.
+func asyncPartitions(n int, fn func(start, end int)) {
+	var wg sync.WaitGroup
+	numPartitions := runtime.GOMAXPROCS(0)
+	valsPerPartition := n / numPartitions
+	for i := 0; i < numPartitions; i++ {
+		start := i * valsPerPartition
+		end := (i + 1) * valsPerPartition
+		if i == numPartitions-1 {
+			end = n // residual values go in last partition
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			fn(start, end)
+			wg.Done()
+		}(start, end)
+	}
+	wg.Wait()
+}
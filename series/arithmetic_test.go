@@ -0,0 +1,176 @@
+package series
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ptiger10/pd/options"
+)
+
+func TestSeries_Arithmetic(t *testing.T) {
+	ints := MustNew([]int{1, 2, 3}, Config{Index: []string{"a", "b", "c"}})
+	ints2 := MustNew([]int{10, 20, 30}, Config{Index: []string{"c", "b", "d"}})
+	floats := MustNew([]float64{1.5, 2, 3}, Config{Index: []string{"a", "b", "c"}})
+	union := []string{"a", "b", "c", "d"}
+	type args struct {
+		fn        func(*Series, *Series, interface{}) (*Series, error)
+		other     *Series
+		fillValue interface{}
+	}
+	tests := []struct {
+		name    string
+		input   *Series
+		args    args
+		want    *Series
+		wantErr bool
+	}{
+		{name: "add same index", input: ints, args: args{(*Series).Add, ints, nil},
+			want: MustNew([]int{2, 4, 6}, Config{Index: []string{"a", "b", "c"}}), wantErr: false},
+		{"add default index", MustNew([]int{1, 2}), args{(*Series).Add, MustNew([]int{3, 4}), nil},
+			MustNew([]int{4, 6}), false},
+		{"add union", ints, args{(*Series).Add, ints2, nil},
+			MustNew([]interface{}{"", 22, 13, ""}, Config{Index: union, DataType: options.Int64}), false},
+		{"add fill", ints, args{(*Series).Add, ints2, 0},
+			MustNew([]int{1, 22, 13, 30}, Config{Index: union}), false},
+		{"sub", ints, args{(*Series).Sub, ints2, 0},
+			MustNew([]int{1, -18, -7, -30}, Config{Index: union}), false},
+		{"mul", ints, args{(*Series).Mul, ints2, 1},
+			MustNew([]int{1, 40, 30, 30}, Config{Index: union}), false},
+		{"div returns float", ints, args{(*Series).Div, ints, nil},
+			MustNew([]float64{1, 1, 1}, Config{Index: []string{"a", "b", "c"}}), false},
+		{"mod", ints2, args{(*Series).Mod, ints, nil},
+			MustNew([]interface{}{1, 0, "", ""}, Config{Index: []string{"c", "b", "d", "a"}, DataType: options.Int64}), false},
+		{"int mod zero is null", MustNew(1), args{(*Series).Mod, MustNew(0), nil},
+			MustNew([]interface{}{""}, Config{DataType: options.Int64}), false},
+		{"pow", ints, args{(*Series).Pow, ints, nil},
+			MustNew([]int{1, 4, 27}, Config{Index: []string{"a", "b", "c"}}), false},
+		{"int pow negative is null", MustNew(2), args{(*Series).Pow, MustNew(-1), nil},
+			MustNew([]interface{}{""}, Config{DataType: options.Int64}), false},
+		{"float", floats, args{(*Series).Mul, ints, nil},
+			MustNew([]float64{1.5, 4, 9}, Config{Index: []string{"a", "b", "c"}}), false},
+		{"float fill", ints, args{(*Series).Add, ints2, 0.5},
+			MustNew([]float64{1.5, 22, 13, 30.5}, Config{Index: union}), false},
+		{"bool", MustNew([]bool{true, false}), args{(*Series).Add, MustNew([]int{1, 1}), nil},
+			MustNew([]int{2, 1}), false},
+		{"multi index",
+			MustNew([]int{1, 2}, Config{MultiIndex: []interface{}{[]string{"a", "a"}, []int{1, 2}}}),
+			args{(*Series).Add, MustNew([]int{10}, Config{MultiIndex: []interface{}{[]string{"a"}, []int{2}}}), 0},
+			MustNew([]int{1, 12}, Config{MultiIndex: []interface{}{[]string{"a", "a"}, []int{1, 2}}}), false},
+		{"duplicate labels paired in order", MustNew([]int{1, 2}, Config{Index: []string{"a", "a"}}),
+			args{(*Series).Add, MustNew([]int{10, 20, 30}, Config{Index: []string{"a", "a", "a"}}), 0},
+			MustNew([]int{11, 22, 30}, Config{Index: []string{"a", "a", "a"}}), false},
+		{"fail: string", MustNew("foo"), args{(*Series).Add, ints, nil},
+			newEmptySeries(), true},
+		{"fail: string other", ints, args{(*Series).Add, MustNew("foo"), nil},
+			newEmptySeries(), true},
+		{"fail: fillValue", ints, args{(*Series).Add, ints, "foo"},
+			newEmptySeries(), true},
+		{"fail: levels", ints, args{(*Series).Add, MustNew(1, Config{MultiIndex: []interface{}{"a", 1}}), nil},
+			newEmptySeries(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.args.fn(tt.input, tt.args.other, tt.args.fillValue)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Series arithmetic error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Series arithmetic = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeries_Arithmetic_null(t *testing.T) {
+	s := MustNew([]float64{1, math.NaN()}, Config{Index: []int{1, 2}})
+	got, err := s.Div(MustNew([]int{2, 3}, Config{Index: []int{2, 1}}), nil)
+	if err != nil {
+		t.Errorf("Series.Div() error: %v", err)
+	}
+	if got.At(0) != 1.0/3 {
+		t.Errorf("Series.Div() returned %v, want %v", got.At(0), 1.0/3)
+	}
+	if !got.values.Null(1) {
+		t.Errorf("Series.Div() returned non-null value for null input")
+	}
+}
+
+func TestSeries_Arithmetic_async(t *testing.T) {
+	n := 1000
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = float64(i)
+	}
+	s := MustNew(vals)
+	got, _ := s.Mul(s, nil)
+	options.SetAsync(false)
+	gotSync, _ := s.Mul(s, nil)
+	options.RestoreDefaults()
+	if !Equal(got, gotSync) {
+		t.Errorf("Series.Mul() async returned %v, want %v", got, gotSync)
+	}
+	if got.At(n-1) != float64((n-1)*(n-1)) {
+		t.Errorf("Series.Mul() returned %v, want %v", got.At(n-1), (n-1)*(n-1))
+	}
+}
+
+func TestSeries_ArithmeticScalar(t *testing.T) {
+	ints := MustNew([]int{1, 2, 3})
+	type args struct {
+		fn    func(*Series, interface{}) (*Series, error)
+		value interface{}
+	}
+	tests := []struct {
+		name    string
+		input   *Series
+		args    args
+		want    *Series
+		wantErr bool
+	}{
+		{name: "add", input: ints, args: args{(*Series).AddScalar, 1},
+			want: MustNew([]int{2, 3, 4}), wantErr: false},
+		{"sub", ints, args{(*Series).SubScalar, 1},
+			MustNew([]int{0, 1, 2}), false},
+		{"mul float", ints, args{(*Series).MulScalar, 0.5},
+			MustNew([]float64{0.5, 1, 1.5}), false},
+		{"div", ints, args{(*Series).DivScalar, 2},
+			MustNew([]float64{0.5, 1, 1.5}), false},
+		{"mod", ints, args{(*Series).ModScalar, 2},
+			MustNew([]int{1, 0, 1}), false},
+		{"pow", ints, args{(*Series).PowScalar, 2},
+			MustNew([]int{1, 4, 9}), false},
+		{"pow large exponent", MustNew([]int{1, -1, 0}), args{(*Series).PowScalar, int64(1e18)},
+			MustNew([]int{1, 1, 0}), false},
+		{"pow overflow is null", MustNew([]int{2, -2, 3}), args{(*Series).PowScalar, 63},
+			MustNew([]interface{}{"", math.MinInt64, ""}, Config{DataType: options.Int64}), false},
+		{"pow largest int64", MustNew([]int{2}), args{(*Series).PowScalar, 62},
+			MustNew([]int{1 << 62}), false},
+		{"add overflow is null", MustNew([]int{math.MaxInt64 - 1, math.MaxInt64, math.MinInt64}), args{(*Series).AddScalar, 1},
+			MustNew([]interface{}{math.MaxInt64, "", math.MinInt64 + 1}, Config{DataType: options.Int64}), false},
+		{"add negative overflow is null", MustNew([]int{math.MinInt64, math.MaxInt64}), args{(*Series).AddScalar, -1},
+			MustNew([]interface{}{"", math.MaxInt64 - 1}, Config{DataType: options.Int64}), false},
+		{"sub overflow is null", MustNew([]int{math.MinInt64, 0}), args{(*Series).SubScalar, 1},
+			MustNew([]interface{}{"", -1}, Config{DataType: options.Int64}), false},
+		{"sub negative overflow is null", MustNew([]int{0, -1}), args{(*Series).SubScalar, math.MinInt64},
+			MustNew([]interface{}{"", math.MaxInt64}, Config{DataType: options.Int64}), false},
+		{"mul overflow is null", MustNew([]int{1 << 62, -(1 << 62), math.MinInt64}), args{(*Series).MulScalar, 2},
+			MustNew([]interface{}{"", math.MinInt64, ""}, Config{DataType: options.Int64}), false},
+		{"keeps name", MustNew([]int{1}, Config{Name: "foo"}), args{(*Series).AddScalar, 1},
+			MustNew([]int{2}, Config{Name: "foo"}), false},
+		{"fail: string scalar", ints, args{(*Series).AddScalar, "foo"},
+			newEmptySeries(), true},
+		{"fail: string series", MustNew("foo"), args{(*Series).AddScalar, 1},
+			newEmptySeries(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.args.fn(tt.input, tt.args.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Series scalar arithmetic error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Series scalar arithmetic = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package series
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ptiger10/pd/internal/values"
+	"github.com/ptiger10/pd/options"
+)
+
+// Cut bins the values of a float64 or int64 Series into the intervals between consecutive edges,
+// and returns a new string Series of interval labels with the same index and name.
+// Edges must be strictly increasing. Null values and values outside every interval are null.
+// See CutOptions for labeling and closing the intervals.
+func (s *Series) Cut(edges []float64, config ...CutOptions) (*Series, error) {
+	tmp := CutOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return newEmptySeries(), fmt.Errorf("Series.Cut(): can supply at most one CutOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	ret, err := s.cut(edges, tmp)
+	if err != nil {
+		return newEmptySeries(), fmt.Errorf("Series.Cut(): %v", err)
+	}
+	return ret, nil
+}
+
+// QCut bins the values of a float64 or int64 Series into q intervals that each contain about the same number of values,
+// and returns a new string Series of interval labels (see Cut) plus the computed edges.
+// The edges are the minimum, the quantiles at 1/q, 2/q, ..., and the maximum of the non-null values.
+// If q is 2 or 4, the quantiles are the median or the quartiles, as in Series.Median and Series.Quartile.
+// Otherwise, quantiles are interpolated linearly between the closest ranks (see Series.Quantile).
+// Intervals are always right-closed, and the first interval includes the minimum, so only config.Labels is used.
+// Returns an error if there are too few values for q intervals or if any edges are duplicated.
+func (s *Series) QCut(q int, config ...CutOptions) (*Series, []float64, error) {
+	tmp := CutOptions{}
+	if config != nil {
+		if len(config) > 1 {
+			return newEmptySeries(), nil, fmt.Errorf("Series.QCut(): can supply at most one CutOptions (%d > 1)", len(config))
+		}
+		tmp = config[0]
+	}
+	if q < 1 {
+		return newEmptySeries(), nil, fmt.Errorf("Series.QCut(): q must be at least 1 (%d)", q)
+	}
+	edges, err := s.quantileEdges(q)
+	if err != nil {
+		return newEmptySeries(), nil, fmt.Errorf("Series.QCut(): %v", err)
+	}
+	ret, err := s.cut(edges, CutOptions{Labels: tmp.Labels, Right: true, IncludeLowest: true})
+	if err != nil {
+		return newEmptySeries(), nil, fmt.Errorf("Series.QCut(): %v", err)
+	}
+	return ret, edges, nil
+}
+
+func (s *Series) cut(edges []float64, config CutOptions) (*Series, error) {
+	if s.datatype != options.Float64 && s.datatype != options.Int64 {
+		return nil, fmt.Errorf("unable to bin DataType %v", s.datatype)
+	}
+	if len(edges) < 2 {
+		return nil, fmt.Errorf("must supply at least two edges")
+	}
+	for k := 1; k < len(edges); k++ {
+		if !(edges[k] > edges[k-1]) {
+			return nil, fmt.Errorf("edges must be strictly increasing (%v)", edges)
+		}
+	}
+	labels := config.Labels
+	if labels == nil {
+		labels = intervalLabels(edges, config)
+	} else if len(labels) != len(edges)-1 {
+		return nil, fmt.Errorf("number of labels must be one fewer than number of edges (%d != %d)", len(labels), len(edges)-1)
+	}
+
+	binned := make([]string, s.Len())
+	data := ensureFloatFromNumerics(s.values.Vals())
+	for i, d := range data {
+		if s.values.Null(i) {
+			continue
+		}
+		if k := bin(d, edges, config); k != -1 {
+			binned[i] = labels[k]
+		}
+	}
+	container := values.MustCreateValuesFromInterface(binned)
+	return FromInternalComponents(container, s.index.Copy(), s.name), nil
+}
+
+// bin returns the position of the interval that contains d, or -1 if none does.
+func bin(d float64, edges []float64, config CutOptions) int {
+	last := len(edges) - 1
+	if config.Right {
+		// first edge greater than or equal to d
+		k := sort.SearchFloat64s(edges, d)
+		switch {
+		case k == 0 && d == edges[0] && config.IncludeLowest:
+			return 0
+		case k == 0 || k > last:
+			return -1
+		}
+		return k - 1
+	}
+	// first edge greater than d
+	k := sort.Search(len(edges), func(k int) bool { return edges[k] > d })
+	if k == 0 || k > last {
+		return -1
+	}
+	return k - 1
+}
+
+// intervalLabels labels the intervals between consecutive edges, e.g., "(0, 18]".
+func intervalLabels(edges []float64, config CutOptions) []string {
+	labels := make([]string, len(edges)-1)
+	for k := range labels {
+		left, right := "[", ")"
+		if config.Right {
+			left, right = "(", "]"
+			if k == 0 && config.IncludeLowest {
+				left = "["
+			}
+		}
+		labels[k] = fmt.Sprintf("%v%v, %v%v", left, edges[k], edges[k+1], right)
+	}
+	return labels
+}
+
+// quantileEdges returns the minimum, the quantiles at 1/q, 2/q, ... and the maximum of the non-null values.
+// The median and quartiles share their logic with Series.Median and Series.Quartile.
+func (s *Series) quantileEdges(q int) ([]float64, error) {
+	if s.datatype != options.Float64 && s.datatype != options.Int64 {
+		return nil, fmt.Errorf("unable to bin DataType %v", s.datatype)
+	}
+	data := ensureFloatFromNumerics(s.validVals())
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no values to bin")
+	}
+	if len(data) < q {
+		return nil, fmt.Errorf("too few values for %d intervals (%d)", q, len(data))
+	}
+	c := make([]float64, len(data))
+	copy(c, data)
+	sort.Float64s(c)
+
+	var inner []float64
+	switch q {
+	case 2:
+		inner = []float64{median(c)}
+	case 4:
+		inner = s.quartiles()
+	default:
+		for k := 1; k < q; k++ {
+			inner = append(inner, quantile(c, float64(k)/float64(q)))
+		}
+	}
+	edges := append([]float64{c[0]}, inner...)
+	edges = append(edges, c[len(c)-1])
+	for k := 1; k < len(edges); k++ {
+		if edges[k] == edges[k-1] {
+			return nil, fmt.Errorf("duplicate edges: %v", edges)
+		}
+	}
+	return edges, nil
+}
@@ -0,0 +1,101 @@
+package series
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ptiger10/pd/options"
+)
+
+func TestSeries_Cut(t *testing.T) {
+	s := MustNew([]interface{}{0, 5, 18, 30, "", 100}, Config{DataType: options.Int64, Index: []string{"a", "b", "c", "d", "e", "f"}, Name: "age"})
+	idx := Config{Index: []string{"a", "b", "c", "d", "e", "f"}, Name: "age"}
+	type args struct {
+		edges  []float64
+		config []CutOptions
+	}
+	tests := []struct {
+		name    string
+		input   *Series
+		args    args
+		want    *Series
+		wantErr bool
+	}{
+		{name: "default left-closed", input: s, args: args{[]float64{0, 18, 65}, nil},
+			want:    MustNew([]string{"[0, 18)", "[0, 18)", "[18, 65)", "[18, 65)", "", ""}, idx),
+			wantErr: false},
+		{"right-closed", s, args{[]float64{0, 18, 65}, []CutOptions{{Right: true}}},
+			MustNew([]string{"", "(0, 18]", "(0, 18]", "(18, 65]", "", ""}, idx),
+			false},
+		{"include lowest", s, args{[]float64{0, 18, 65}, []CutOptions{{Right: true, IncludeLowest: true}}},
+			MustNew([]string{"[0, 18]", "[0, 18]", "[0, 18]", "(18, 65]", "", ""}, idx),
+			false},
+		{"labels", s, args{[]float64{0, 18, 65, 120}, []CutOptions{{Labels: []string{"child", "adult", "senior"}}}},
+			MustNew([]string{"child", "child", "adult", "adult", "", "senior"}, idx),
+			false},
+		{"float", MustNew([]float64{0.5, 1.5}), args{[]float64{0, 1, 2}, nil},
+			MustNew([]string{"[0, 1)", "[1, 2)"}),
+			false},
+		{"fail: too few edges", s, args{[]float64{0}, nil}, newEmptySeries(), true},
+		{"fail: unsorted edges", s, args{[]float64{0, 18, 18}, nil}, newEmptySeries(), true},
+		{"fail: wrong number of labels", s, args{[]float64{0, 18}, []CutOptions{{Labels: []string{"foo", "bar"}}}}, newEmptySeries(), true},
+		{"fail: unsupported DataType", MustNew([]string{"foo"}), args{[]float64{0, 18}, nil}, newEmptySeries(), true},
+		{"fail: multiple configs", s, args{[]float64{0, 18}, []CutOptions{{}, {}}}, newEmptySeries(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.Cut(tt.args.edges, tt.args.config...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Series.Cut() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Series.Cut() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeries_QCut(t *testing.T) {
+	s := MustNew([]float64{1, 2, 3, 4, 5, 6, 7, 8})
+	tests := []struct {
+		name      string
+		input     *Series
+		q         int
+		config    []CutOptions
+		want      *Series
+		wantEdges []float64
+		wantErr   bool
+	}{
+		{name: "quartiles", input: s, q: 4, config: nil,
+			want:      MustNew([]string{"[1, 2.5]", "[1, 2.5]", "(2.5, 4.5]", "(2.5, 4.5]", "(4.5, 6.5]", "(4.5, 6.5]", "(6.5, 8]", "(6.5, 8]"}),
+			wantEdges: []float64{1, s.Quartile(1), s.Quartile(2), s.Quartile(3), 8},
+			wantErr:   false},
+		{"median with labels", s, 2, []CutOptions{{Labels: []string{"low", "high"}}},
+			MustNew([]string{"low", "low", "low", "low", "high", "high", "high", "high"}),
+			[]float64{1, s.Median(), 8},
+			false},
+		{"interpolated", MustNew([]int{0, 10, 20, 30}), 3, nil,
+			MustNew([]string{"[0, 10]", "[0, 10]", "(10, 20]", "(20, 30]"}),
+			[]float64{0, 10, 20, 30},
+			false},
+		{"fail: duplicate edges", MustNew([]int{1, 1, 1, 2}), 4, nil, newEmptySeries(), nil, true},
+		{"fail: too few values", MustNew([]int{1, 2}), 4, nil, newEmptySeries(), nil, true},
+		{"fail: q < 1", s, 0, nil, newEmptySeries(), nil, true},
+		{"fail: no values", MustNew([]float64{}), 2, nil, newEmptySeries(), nil, true},
+		{"fail: multiple configs", s, 2, []CutOptions{{}, {}}, newEmptySeries(), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotEdges, err := tt.input.QCut(tt.q, tt.config...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Series.QCut() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Series.QCut() got %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotEdges, tt.wantEdges) {
+				t.Errorf("Series.QCut() edges = %v, want %v", gotEdges, tt.wantEdges)
+			}
+		})
+	}
+}
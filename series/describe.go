@@ -341,6 +341,38 @@ func (s *Series) Unique() []string {
 	return ret
 }
 
+// Count returns the number of non-null values in the Series.
+func (s *Series) Count() int {
+	return s.validCount()
+}
+
+// NUnique returns the number of unique non-null values in the Series.
+// Values are unique by type and value (see Mode), so int64 1 and "1" in an interface Series are distinct.
+func (s *Series) NUnique() int {
+	seen := make(map[string]bool)
+	for _, i := range s.valid() {
+		seen[values.Key(s.values.Value(i))] = true
+	}
+	return len(seen)
+}
+
+// Mode returns the most common non-null value in the Series, or nil if there are none.
+// Ties are broken by first appearance.
+func (s *Series) Mode() interface{} {
+	counts := make(map[string]int)
+	var mode interface{}
+	var max int
+	for _, i := range s.valid() {
+		val := s.values.Value(i)
+		key := values.Key(val)
+		counts[key]++
+		if counts[key] > max {
+			mode, max = val, counts[key]
+		}
+	}
+	return mode
+}
+
 // ValueCounts returns a map of non-null value labels to number of occurrences in the Series.
 func (s *Series) ValueCounts() map[string]int {
 	vals := s.DropNull().Values()
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"log"
 	"sort"
-	"strings"
 	"sync"
 
+	"github.com/ptiger10/pd/internal/index"
 	"github.com/ptiger10/pd/internal/values"
 	"github.com/ptiger10/pd/options"
 )
@@ -14,6 +14,7 @@ import (
 type group struct {
 	Positions     []int
 	FirstPosition int
+	Key           GroupKey
 }
 
 func (grp *group) copy() *group {
@@ -21,7 +22,9 @@ func (grp *group) copy() *group {
 	for i, p := range grp.Positions {
 		pos[i] = p
 	}
-	return &group{Positions: pos, FirstPosition: grp.FirstPosition}
+	labels := make([]interface{}, len(grp.Key.Labels))
+	copy(labels, grp.Key.Labels)
+	return &group{Positions: pos, FirstPosition: grp.FirstPosition, Key: GroupKey{Labels: labels}}
 }
 
 // copy a grouping
@@ -30,9 +33,13 @@ func (g Grouping) copy() Grouping {
 	for k, v := range g.groups {
 		grps[k] = v.copy()
 	}
+	order := make([]string, len(g.order))
+	copy(order, g.order)
 	return Grouping{
 		s:      g.s.Copy(),
 		groups: grps,
+		source: g.source,
+		order:  order,
 	}
 }
 
@@ -46,7 +53,7 @@ func (g Grouping) asyncMath(fn func(*Series) float64) *Series {
 	// synchronous option
 	if !options.GetAsync() {
 		ret := newEmptySeries()
-		for _, group := range g.Groups() {
+		for _, group := range g.ids() {
 			s := g.math(group, fn)
 			ret.InPlace.Join(s)
 		}
@@ -55,7 +62,7 @@ func (g Grouping) asyncMath(fn func(*Series) float64) *Series {
 
 	// asynchronous option
 	ch := make(chan calcReturn, g.Len())
-	for i, group := range g.Groups() {
+	for i, group := range g.ids() {
 		wg.Add(1)
 		go g.awaitMath(ch, i, group, fn, &wg)
 	}
@@ -102,27 +109,73 @@ func (g Grouping) math(group string, fn func(*Series) float64) *Series {
 	return s
 }
 
-// Groups returns all valid group labels in the Grouping.
+// Groups returns all valid group labels in the Grouping, sorted in alphabetical order.
+// Each label is the String() of the group's GroupKey.
 func (g Grouping) Groups() []string {
-	var keys []string
-	for k := range g.groups {
-		keys = append(keys, k)
+	var labels []string
+	for _, id := range g.ids() {
+		labels = append(labels, g.groups[id].Key.String())
+	}
+	return labels
+}
+
+// Keys returns the GroupKey of every group in the Grouping, in g.Groups() order.
+func (g Grouping) Keys() []GroupKey {
+	var keys []GroupKey
+	for _, id := range g.ids() {
+		keys = append(keys, g.groups[id].copy().Key)
 	}
-	sort.Strings(keys)
 	return keys
 }
 
+// ids returns the hashes that identify the groups in g.groups, sorted by group label and then by original group position.
+func (g Grouping) ids() []string {
+	ids := make([]string, len(g.order))
+	copy(ids, g.order)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return g.groups[ids[i]].Key.String() < g.groups[ids[j]].Key.String()
+	})
+	return ids
+}
+
 // Len returns the number of groups in the Grouping.
 func (g Grouping) Len() int {
 	return len(g.groups)
 }
 
 // Group returns the Series with the given group label, or an error if that label does not exist.
+// If several groups share the label (e.g., the labels 1 and "1"), the first group is returned. Use GroupByKey to select among them.
 func (g Grouping) Group(label string) *Series {
-	group, ok := g.groups[label]
+	for _, id := range g.ids() {
+		if g.groups[id].Key.String() == label {
+			s, _ := g.s.Subset(g.groups[id].Positions)
+			return s
+		}
+	}
+	if options.GetLogWarnings() {
+		log.Printf("s.Grouping.Group(): label %v not in g.Groups()", label)
+	}
+	return newEmptySeries()
+}
+
+// GroupByKey returns the Series of the group with the given label in each grouped index level, in level order.
+// Labels match only labels of the same type. In typed index levels, Go scalars are first converted to their DataType (e.g., int to Int64).
+// If no group has those labels, an empty Series is returned.
+func (g Grouping) GroupByKey(labels ...interface{}) *Series {
+	key := make([]interface{}, len(labels))
+	for j, label := range labels {
+		key[j] = label
+		if j >= g.s.NumLevels() || g.s.index.Levels[j].DataType == options.Interface {
+			continue
+		}
+		if container, err := values.ScalarFactory(label); err == nil {
+			key[j] = container.Values.Value(0)
+		}
+	}
+	group, ok := g.groups[values.MultiKey(key)]
 	if !ok {
 		if options.GetLogWarnings() {
-			log.Printf("s.Grouping.Group(): label %v not in g.Groups()", label)
+			log.Printf("s.Grouping.GroupByKey(): key %v not in g.Keys()", key)
 		}
 		return newEmptySeries()
 	}
@@ -139,6 +192,7 @@ func newEmptyGrouping() Grouping {
 // GroupByIndex groups a Series by one or more of its index levels. If no int is provided, all index levels are used.
 func (s *Series) GroupByIndex(levelPositions ...int) Grouping {
 	groups := make(map[string]*group)
+	source := s
 	if len(levelPositions) != 0 {
 		var err error
 		s = s.Copy()
@@ -151,20 +205,14 @@ func (s *Series) GroupByIndex(levelPositions ...int) Grouping {
 		}
 	}
 
-	for i := 0; i < s.Len(); i++ {
-		labels := s.index.Elements(i).Labels
-		var strLabels []string
-		for _, label := range labels {
-			strLabels = append(strLabels, fmt.Sprint(label))
-		}
-		groupLabel := strings.Join(strLabels, values.GetMultiColNameSeparator())
-
-		if _, ok := groups[groupLabel]; !ok {
-			groups[groupLabel] = &group{FirstPosition: i}
-		}
-		groups[groupLabel].Positions = append(groups[groupLabel].Positions, i)
+	codes, numCodes := s.index.Factorize()
+	order := make([]string, numCodes)
+	for k, positions := range values.GroupPositions(codes, numCodes) {
+		grp := &group{Positions: positions, FirstPosition: positions[0], Key: GroupKey{Labels: s.index.Elements(positions[0]).Labels}}
+		order[k] = values.MultiKey(grp.Key.Labels)
+		groups[order[k]] = grp
 	}
-	return Grouping{s: s, groups: groups}
+	return Grouping{s: s, groups: groups, source: source, order: order}
 }
 
 // First returns the first occurrence of each grouping in the Series.
@@ -175,7 +223,7 @@ func (g Grouping) First() *Series {
 		return s
 	}
 	ret := newEmptySeries()
-	for _, group := range g.Groups() {
+	for _, group := range g.ids() {
 		s := first(group)
 		ret.InPlace.Join(s)
 	}
@@ -191,7 +239,7 @@ func (g Grouping) Last() *Series {
 		return s
 	}
 	ret := newEmptySeries()
-	for _, group := range g.Groups() {
+	for _, group := range g.ids() {
 		s := last(group)
 		ret.InPlace.Join(s)
 	}
@@ -227,3 +275,232 @@ func (g Grouping) Median() *Series {
 func (g Grouping) Std() *Series {
 	return g.asyncMath((*Series).Std)
 }
+
+// Var for each group in the Grouping.
+func (g Grouping) Var() *Series {
+	return g.asyncMath((*Series).Var)
+}
+
+// Prod for each group in the Grouping.
+func (g Grouping) Prod() *Series {
+	return g.asyncMath((*Series).Prod)
+}
+
+// Quantile q for each group in the Grouping.
+func (g Grouping) Quantile(q float64) *Series {
+	return g.asyncMath(func(s *Series) float64 { return s.Quantile(q) })
+}
+
+// Count of non-null values for each group in the Grouping.
+func (g Grouping) Count() *Series {
+	return g.Reduce(AggCount)
+}
+
+// Size of each group in the Grouping, including null values.
+func (g Grouping) Size() *Series {
+	return g.Reduce(AggSize)
+}
+
+// NUnique returns the number of unique non-null values for each group in the Grouping.
+func (g Grouping) NUnique() *Series {
+	return g.Reduce(AggNUnique)
+}
+
+// Mode returns the most common non-null value for each group in the Grouping.
+func (g Grouping) Mode() *Series {
+	return g.Reduce(AggMode)
+}
+
+// FirstValid returns the first non-null value for each group in the Grouping.
+func (g Grouping) FirstValid() *Series {
+	return g.Reduce(AggFirst)
+}
+
+// LastValid returns the last non-null value for each group in the Grouping.
+func (g Grouping) LastValid() *Series {
+	return g.Reduce(AggLast)
+}
+
+// Nth returns the value at position n of each group in the Grouping, counting from the end if n is negative.
+// The value is null if n is out of range for the group.
+func (g Grouping) Nth(n int) *Series {
+	return g.Reduce(AggNth(n))
+}
+
+// Reduce applies agg to each group in the Grouping and returns a new Series with one value per group, in g.Groups() order.
+// The result has the common DataType of the reduced values. Groups are reduced concurrently if options.GetAsync() is true.
+func (g Grouping) Reduce(agg Agg) *Series {
+	if g.Len() == 0 {
+		return newEmptySeries()
+	}
+	groups := g.ids()
+	results := make([]interface{}, len(groups))
+	firstPositions := make([]int, len(groups))
+	values.ForEachGroup(len(groups), func(k int) {
+		grp := g.groups[groups[k]]
+		firstPositions[k] = grp.FirstPosition
+		results[k] = agg.Eval(g.s.subset(grp.Positions))
+	})
+	return FromInternalComponents(values.ScalarSliceFactory(results), g.s.subsetIndex(firstPositions), g.s.name)
+}
+
+// Transform calls fn with the values of each group in the Grouping and returns a new Series with the results
+// in the original index and row order of the grouped Series. fn may return a single value, which fills every row in the group
+// (e.g., a group total), or one value per row in the group (e.g., a within-group z-score), in group order.
+// The result has the common DataType of the returned values. Groups are transformed concurrently if options.GetAsync() is true.
+// Returns an error if fn returns nil or any other number of values for a group.
+func (g Grouping) Transform(fn func(*Series) *Series) (*Series, error) {
+	if g.Len() == 0 {
+		return newEmptySeries(), nil
+	}
+	groups := g.ids()
+	results := make([]*Series, len(groups))
+	values.ForEachGroup(len(groups), func(k int) {
+		results[k] = fn(g.s.subset(g.groups[groups[k]].Positions))
+	})
+	dataTypes := make([]options.DataType, len(groups))
+	for k, result := range results {
+		if result == nil {
+			return newEmptySeries(), fmt.Errorf("Grouping.Transform(): group %v: fn returned nil", g.groups[groups[k]].Key)
+		}
+		if n := len(g.groups[groups[k]].Positions); result.Len() != 1 && result.Len() != n {
+			return newEmptySeries(), fmt.Errorf("Grouping.Transform(): group %v: fn returned %d values, want 1 or %d", g.groups[groups[k]].Key, result.Len(), n)
+		}
+		dataTypes[k] = result.datatype
+	}
+	dataType := values.CommonDataType(dataTypes...)
+	container := values.MakeNullContainer(g.s.Len(), dataType)
+	for k, result := range results {
+		// ducks error because dataType is the DataType of an existing Series
+		converted, _ := values.Convert(result.values, dataType)
+		for i, pos := range g.groups[groups[k]].Positions {
+			src := i
+			if result.Len() == 1 {
+				src = 0
+			}
+			if !converted.Null(src) {
+				container.Values.Set(pos, converted.Value(src))
+			}
+		}
+	}
+	return FromInternalComponents(container, g.source.index.Copy(), g.s.name), nil
+}
+
+// TransformAgg applies agg to each group in the Grouping and returns a new Series in which every row has the result for its group,
+// in the original index and row order of the grouped Series. See Transform.
+func (g Grouping) TransformAgg(agg Agg) *Series {
+	// ducks error because fn always returns a single value
+	ret, _ := g.Transform(func(s *Series) *Series {
+		return FromInternalComponents(values.ScalarSliceFactory([]interface{}{agg.Eval(s)}), index.NewDefault(1), s.name)
+	})
+	return ret
+}
+
+// Filter calls fn with the values of each group in the Grouping and returns a new Series with the original rows
+// of every group for which fn returns true, in their original order. Groups are evaluated concurrently if options.GetAsync() is true.
+func (g Grouping) Filter(fn func(*Series) bool) *Series {
+	if g.Len() == 0 {
+		return newEmptySeries()
+	}
+	groups := g.ids()
+	keep := make([]bool, len(groups))
+	values.ForEachGroup(len(groups), func(k int) {
+		keep[k] = fn(g.s.subset(g.groups[groups[k]].Positions))
+	})
+	var positions []int
+	for k := range groups {
+		if keep[k] {
+			positions = append(positions, g.groups[groups[k]].Positions...)
+		}
+	}
+	sort.Ints(positions)
+	return g.source.subset(positions)
+}
+
+// groupPositions returns the positions of each group in g.Groups() order.
+func (g Grouping) groupPositions() [][]int {
+	groups := g.ids()
+	ret := make([][]int, len(groups))
+	for k, group := range groups {
+		ret[k] = g.groups[group].Positions
+	}
+	return ret
+}
+
+// CumSum returns the cumulative sum of the non-null values in each group of a float64 or int64 Series,
+// in the original index and row order of the grouped Series. Null values are null.
+// The result keeps the DataType of the Series, except that CumProd is always float64.
+// An int64 sum that overflows is null, as is the rest of its group.
+func (g Grouping) CumSum() (*Series, error) {
+	return g.accumulate("CumSum", "sum")
+}
+
+// CumProd returns the cumulative product of the non-null values in each group of a float64 or int64 Series. See CumSum.
+func (g Grouping) CumProd() (*Series, error) {
+	return g.accumulate("CumProd", "prod")
+}
+
+// CumMax returns the cumulative maximum of the non-null values in each group of a float64 or int64 Series. See CumSum.
+func (g Grouping) CumMax() (*Series, error) {
+	return g.accumulate("CumMax", "max")
+}
+
+// CumMin returns the cumulative minimum of the non-null values in each group of a float64 or int64 Series. See CumSum.
+func (g Grouping) CumMin() (*Series, error) {
+	return g.accumulate("CumMin", "min")
+}
+
+func (g Grouping) accumulate(method string, op string) (*Series, error) {
+	if g.Len() == 0 {
+		return newEmptySeries(), nil
+	}
+	container, err := values.Container{Values: g.s.values, DataType: g.s.datatype}.Accumulate(g.groupPositions(), op)
+	if err != nil {
+		return newEmptySeries(), fmt.Errorf("Grouping.%v(): %v", method, err)
+	}
+	return FromInternalComponents(container, g.source.index.Copy(), g.s.name), nil
+}
+
+// CumCount returns the position of each row within its group, starting at 0,
+// in the original index and row order of the grouped Series. The result is named "cumcount", as in DataFrame Grouping.CumCount.
+func (g Grouping) CumCount() *Series {
+	if g.Len() == 0 {
+		return newEmptySeries()
+	}
+	counts := make([]int64, g.s.Len())
+	for _, positions := range g.groupPositions() {
+		for i, pos := range positions {
+			counts[pos] = int64(i)
+		}
+	}
+	return FromInternalComponents(values.MustCreateValuesFromInterface(counts), g.source.index.Copy(), "cumcount")
+}
+
+// NGroup returns the number of the group of each row, starting at 0 in order of first appearance (not g.Groups() order),
+// in the original index and row order of the grouped Series. The result is named "ngroup", as in DataFrame Grouping.NGroup.
+func (g Grouping) NGroup() *Series {
+	if g.Len() == 0 {
+		return newEmptySeries()
+	}
+	numbers := make([]int64, g.s.Len())
+	for k, id := range g.order {
+		for _, pos := range g.groups[id].Positions {
+			numbers[pos] = int64(k)
+		}
+	}
+	return FromInternalComponents(values.MustCreateValuesFromInterface(numbers), g.source.index.Copy(), "ngroup")
+}
+
+// Rank returns the ascending rank, starting at 1, of each non-null value within its group,
+// in the original index and row order of the grouped Series. Null values are null.
+// Tied values share the average of their ranks, unless method is "min", "max", "first" or "dense".
+func (g Grouping) Rank(method string) (*Series, error) {
+	if g.Len() == 0 {
+		return newEmptySeries(), nil
+	}
+	container, err := values.Container{Values: g.s.values, DataType: g.s.datatype}.Rank(g.groupPositions(), method)
+	if err != nil {
+		return newEmptySeries(), fmt.Errorf("Grouping.Rank(): %v", err)
+	}
+	return FromInternalComponents(container, g.source.index.Copy(), g.s.name), nil
+}
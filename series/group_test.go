@@ -11,9 +11,18 @@ import (
 	"github.com/ptiger10/pd/options"
 )
 
+// groupsByLabel returns the positions of every group in g keyed by group label, for comparison with literal groups.
+func groupsByLabel(g Grouping) map[string]*group {
+	ret := make(map[string]*group)
+	for _, grp := range g.groups {
+		ret[grp.Key.String()] = &group{Positions: grp.Positions, FirstPosition: grp.FirstPosition}
+	}
+	return ret
+}
+
 func TestGroup_Copy(t *testing.T) {
 	s := MustNew([]int{1, 2, 3, 4}, Config{Index: []int{1, 1, 2, 2}})
-	got := s.GroupByIndex(0).copy().groups
+	got := groupsByLabel(s.GroupByIndex(0).copy())
 	want := map[string]*group{
 		"1": {Positions: []int{0, 1}, FirstPosition: 0},
 		"2": {Positions: []int{2, 3}, FirstPosition: 2},
@@ -45,6 +54,12 @@ func TestGrouping_Math(t *testing.T) {
 			MustNew([]float64{1.5, 3.5}, Config{Index: []int{1, 2}})},
 		{"standard deviation", s, Grouping.Std,
 			MustNew([]float64{0.5, 0.5}, Config{Index: []int{1, 2}})},
+		{"variance", s, Grouping.Var,
+			MustNew([]float64{0.25, 0.25}, Config{Index: []int{1, 2}})},
+		{"product", s, Grouping.Prod,
+			MustNew([]float64{2, 12}, Config{Index: []int{1, 2}})},
+		{"quantile", s, func(g Grouping) *Series { return g.Quantile(0.75) },
+			MustNew([]float64{1.75, 3.75}, Config{Index: []int{1, 2}})},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -107,11 +122,11 @@ func TestSeries_GroupByIndex(t *testing.T) {
 		{"fail: invalid level",
 			multi,
 			args{[]int{10}},
-			newEmptyGrouping().groups},
+			groupsByLabel(newEmptyGrouping())},
 		{"fail: partial invalid level",
 			multi,
 			args{[]int{0, 10}},
-			newEmptyGrouping().groups},
+			groupsByLabel(newEmptyGrouping())},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -120,7 +135,7 @@ func TestSeries_GroupByIndex(t *testing.T) {
 			defer log.SetOutput(os.Stderr)
 
 			s := tt.input.Copy()
-			got := s.GroupByIndex(tt.args.levelPositions...).groups
+			got := groupsByLabel(s.GroupByIndex(tt.args.levelPositions...))
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Series.GroupByIndex() = %#v, want %#v", got, tt.want)
 			}
@@ -134,6 +149,73 @@ func TestSeries_GroupByIndex(t *testing.T) {
 	}
 }
 
+func TestGrouping_typedKeys(t *testing.T) {
+	s := MustNew([]int{1, 2, 3, 4},
+		Config{MultiIndex: []interface{}{[]interface{}{1, "1", "a | b", "a"}, []string{"c", "c", "c", "b | c"}}})
+	g := s.GroupByIndex()
+	if g.Len() != 4 {
+		t.Fatalf("Grouping.Len() = %d, want 4 distinct groups", g.Len())
+	}
+	wantKeys := []GroupKey{{[]interface{}{1, "c"}}, {[]interface{}{"1", "c"}}, {[]interface{}{"a | b", "c"}}, {[]interface{}{"a", "b | c"}}}
+	if got := g.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("Grouping.Keys() = %v, want %v", got, wantKeys)
+	}
+	if got := g.Groups(); !reflect.DeepEqual(got, []string{"1 | c", "1 | c", "a | b | c", "a | b | c"}) {
+		t.Errorf("Grouping.Groups() = %v, want sorted labels", got)
+	}
+	if got := g.Group("1 | c"); !Equal(got, s.subset([]int{0})) {
+		t.Errorf("Grouping.Group() = %v, want first group with label", got)
+	}
+
+	tests := []struct {
+		name string
+		key  []interface{}
+		want *Series
+	}{
+		{"int", []interface{}{1, "c"}, s.subset([]int{0})},
+		{"string", []interface{}{"1", "c"}, s.subset([]int{1})},
+		{"separator in first level", []interface{}{"a | b", "c"}, s.subset([]int{2})},
+		{"separator in second level", []interface{}{"a", "b | c"}, s.subset([]int{3})},
+		{"fail: missing key", []interface{}{2, "c"}, newEmptySeries()},
+		{"fail: too few labels", []interface{}{1}, newEmptySeries()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			defer log.SetOutput(os.Stderr)
+
+			got := g.GroupByKey(tt.key...)
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.GroupByKey() = %v, want %v", got, tt.want)
+			}
+			if strings.Contains(tt.name, "fail") {
+				if buf.String() == "" {
+					t.Errorf("Grouping.GroupByKey() returned no log message, want log due to fail")
+				}
+			}
+		})
+	}
+}
+
+func TestGrouping_typedKeyIndex(t *testing.T) {
+	s := MustNew([]float64{1, 2, 3}, Config{Index: []int{2, 1, 2}, IndexName: "key"})
+	want := MustNew([]float64{2, 4}, Config{Index: []int{1, 2}, IndexName: "key"})
+	got := s.GroupByIndex().Sum()
+	if !Equal(got, want) {
+		t.Errorf("Grouping.Sum() = %v, want %v", got, want)
+	}
+	options.SetAsync(false)
+	gotSync := s.GroupByIndex().Sum()
+	options.RestoreDefaults()
+	if !Equal(gotSync, want) {
+		t.Errorf("Grouping.Sum() synchronous = %v, want %v", gotSync, want)
+	}
+	if got := s.GroupByIndex().GroupByKey(2); got.Len() != 2 {
+		t.Errorf("Grouping.GroupByKey() on Int64 level returned %d rows, want 2", got.Len())
+	}
+}
+
 func Test_Group(t *testing.T) {
 	type args struct {
 		label string
@@ -182,3 +264,196 @@ func TestGrouping_Nth(t *testing.T) {
 		t.Errorf("Grouping.Last() = %#v, want %#v", gotLast, wantLast)
 	}
 }
+
+func TestGrouping_reductions(t *testing.T) {
+	s := MustNew([]string{"foo", "", "bar", "baz", "baz", ""}, Config{Index: []int{1, 1, 2, 2, 2, 3}, Name: "qux"})
+	idx := Config{Index: []int{1, 2, 3}, Name: "qux"}
+	tests := []struct {
+		name string
+		fn   func(Grouping) *Series
+		want *Series
+	}{
+		{"count", Grouping.Count, MustNew([]int64{1, 3, 0}, idx)},
+		{"size", Grouping.Size, MustNew([]int64{2, 3, 1}, idx)},
+		{"nunique", Grouping.NUnique, MustNew([]int64{1, 2, 0}, idx)},
+		{"mode", Grouping.Mode, MustNew([]string{"foo", "baz", ""}, idx)},
+		{"first valid", Grouping.FirstValid, MustNew([]string{"foo", "bar", ""}, idx)},
+		{"last valid", Grouping.LastValid, MustNew([]string{"foo", "baz", ""}, idx)},
+		{"nth", func(g Grouping) *Series { return g.Nth(1) }, MustNew([]string{"", "baz", ""}, idx)},
+		{"nth from end", func(g Grouping) *Series { return g.Nth(-1) }, MustNew([]string{"", "baz", ""}, idx)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fn(s.GroupByIndex())
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping reduction = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync := tt.fn(s.GroupByIndex())
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping reduction synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_Reduce(t *testing.T) {
+	s := MustNew([]string{"foo", "", "bar", "baz"}, Config{Index: []int{1, 1, 2, 2}, Name: "qux"})
+	concat := NewAgg("concat", func(s *Series) interface{} {
+		var ret string
+		for _, v := range s.validVals().([]string) {
+			ret += v
+		}
+		return ret
+	})
+	tests := []struct {
+		name  string
+		input *Series
+		agg   Agg
+		want  *Series
+	}{
+		{"count", s, AggCount, MustNew([]int{1, 2}, Config{Index: []int{1, 2}, Name: "qux"})},
+		{"custom string result", s, concat, MustNew([]string{"foo", "barbaz"}, Config{Index: []int{1, 2}, Name: "qux"})},
+		{"empty", newEmptySeries(), AggCount, newEmptySeries()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.GroupByIndex().Reduce(tt.agg)
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.Reduce() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync := tt.input.GroupByIndex().Reduce(tt.agg)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Reduce() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_Transform(t *testing.T) {
+	s := MustNew([]float64{1, 2, 3, 4}, Config{MultiIndex: []interface{}{[]string{"a", "b", "a", "b"}, []int{1, 2, 3, 4}}, Name: "foo"})
+	counter := func(s *Series) *Series {
+		ret := make([]int, s.Len())
+		for i := range ret {
+			ret[i] = i
+		}
+		return MustNew(ret)
+	}
+	tests := []struct {
+		name    string
+		input   *Series
+		fn      func(*Series) *Series
+		want    *Series
+		wantErr bool
+	}{
+		{name: "reduction", input: s, fn: func(s *Series) *Series { return MustNew(s.Sum()) },
+			want:    MustNew([]float64{4, 6, 4, 6}, Config{MultiIndex: []interface{}{[]string{"a", "b", "a", "b"}, []int{1, 2, 3, 4}}, Name: "foo"}),
+			wantErr: false},
+		{"vector", s, counter,
+			MustNew([]int{0, 0, 1, 1}, Config{MultiIndex: []interface{}{[]string{"a", "b", "a", "b"}, []int{1, 2, 3, 4}}, Name: "foo"}),
+			false},
+		{"empty", newEmptySeries(), counter, newEmptySeries(), false},
+		{"fail: nil", s, func(*Series) *Series { return nil }, newEmptySeries(), true},
+		{"fail: wrong length", s, func(*Series) *Series { return MustNew([]int{1, 2, 3}) }, newEmptySeries(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.GroupByIndex(0).Transform(tt.fn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Grouping.Transform() error = %v, want %v", err, tt.wantErr)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.Transform() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync, _ := tt.input.GroupByIndex(0).Transform(tt.fn)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Transform() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_TransformAgg(t *testing.T) {
+	s := MustNew([]int{1, 2, 3, 4}, Config{Index: []string{"a", "b", "a", "b"}})
+	got := s.GroupByIndex().TransformAgg(AggCount)
+	want := MustNew([]int64{2, 2, 2, 2}, Config{Index: []string{"a", "b", "a", "b"}})
+	if !Equal(got, want) {
+		t.Errorf("Grouping.TransformAgg() = %v, want %v", got, want)
+	}
+}
+
+func TestGrouping_Filter(t *testing.T) {
+	s := MustNew([]int{1, 2, 3, 4, 5}, Config{MultiIndex: []interface{}{[]string{"a", "b", "a", "c", "b"}, []int{1, 2, 3, 4, 5}}, Name: "foo"})
+	tests := []struct {
+		name  string
+		input *Series
+		fn    func(*Series) bool
+		want  *Series
+	}{
+		{"keep some", s, func(s *Series) bool { return s.Len() > 1 },
+			MustNew([]int{1, 2, 3, 5}, Config{MultiIndex: []interface{}{[]string{"a", "b", "a", "b"}, []int{1, 2, 3, 5}}, Name: "foo"})},
+		{"keep all", s, func(s *Series) bool { return true }, s},
+		{"keep none", s, func(s *Series) bool { return false }, s.subset(nil)},
+		{"empty", newEmptySeries(), func(s *Series) bool { return true }, newEmptySeries()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.GroupByIndex(0).Filter(tt.fn)
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping.Filter() = %v, want %v", got, tt.want)
+			}
+			options.SetAsync(false)
+			gotSync := tt.input.GroupByIndex(0).Filter(tt.fn)
+			options.RestoreDefaults()
+			if !Equal(gotSync, tt.want) {
+				t.Errorf("Grouping.Filter() synchronous = %v, want %v", gotSync, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrouping_cumulative(t *testing.T) {
+	s := MustNew([]int{3, 2, 1, 4, 5}, Config{MultiIndex: []interface{}{[]string{"b", "a", "b", "a", "b"}, []int{1, 2, 3, 4, 5}}, Name: "foo"})
+	idx := Config{MultiIndex: []interface{}{[]string{"b", "a", "b", "a", "b"}, []int{1, 2, 3, 4, 5}}, Name: "foo"}
+	tests := []struct {
+		name string
+		fn   func(Grouping) (*Series, error)
+		want *Series
+	}{
+		{"sum", Grouping.CumSum, MustNew([]int64{3, 2, 4, 6, 9}, idx)},
+		{"prod", Grouping.CumProd, MustNew([]float64{3, 2, 3, 8, 15}, idx)},
+		{"max", Grouping.CumMax, MustNew([]int64{3, 2, 3, 4, 5}, idx)},
+		{"min", Grouping.CumMin, MustNew([]int64{3, 2, 1, 2, 1}, idx)},
+		{"rank", func(g Grouping) (*Series, error) { return g.Rank("") }, MustNew([]float64{2, 1, 1, 2, 3}, idx)},
+		{"cumcount", func(g Grouping) (*Series, error) { return g.CumCount(), nil },
+			MustNew([]int64{0, 0, 1, 1, 2}, Config{MultiIndex: idx.MultiIndex, Name: "cumcount"})},
+		{"ngroup", func(g Grouping) (*Series, error) { return g.NGroup(), nil },
+			MustNew([]int64{0, 1, 0, 1, 0}, Config{MultiIndex: idx.MultiIndex, Name: "ngroup"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(s.GroupByIndex(0))
+			if err != nil {
+				t.Errorf("Grouping cumulative operation error = %v, want nil", err)
+			}
+			if !Equal(got, tt.want) {
+				t.Errorf("Grouping cumulative operation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	strs := MustNew([]string{"foo", "bar"}, Config{Index: []int{1, 1}})
+	if _, err := strs.GroupByIndex().CumSum(); err == nil {
+		t.Errorf("Grouping.CumSum() on string Series returned nil error, want error")
+	}
+	if _, err := s.GroupByIndex().Rank("random"); err == nil {
+		t.Errorf("Grouping.Rank() with unsupported method returned nil error, want error")
+	}
+	if got, err := newEmptySeries().GroupByIndex().CumSum(); err != nil || !Equal(got, newEmptySeries()) {
+		t.Errorf("Grouping.CumSum() on empty Series = %v, %v, want empty Series", got, err)
+	}
+}
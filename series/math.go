@@ -235,8 +235,20 @@ func (s *Series) Max() float64 {
 //
 // Applies to: Float, Int. If inapplicable, defaults to math.Nan().
 func (s *Series) Std() float64 {
+	return math.Pow(s.variance(), 0.5)
+}
+
+// Var returns the population variance of a series, the square of Std.
+//
+// Applies to: Float, Int. If inapplicable, defaults to math.Nan().
+func (s *Series) Var() float64 {
+	return s.variance()
+}
+
+// variance returns the population variance of the non-null values, computed in partitions if options.GetAsync() is true.
+func (s *Series) variance() float64 {
+	mean := s.Mean()
 	stdFunc := func(data []float64) (variance float64, counter int) {
-		mean := s.Mean()
 		for _, d := range data {
 			if !math.IsNaN(d) {
 				variance += (d - mean) * (d - mean)
@@ -255,7 +267,7 @@ func (s *Series) Std() float64 {
 		data := ensureFloatFromNumerics(s.Vals())
 		if !options.GetAsync() {
 			variance, validCount := stdFunc(data)
-			return math.Pow(variance/float64(validCount), 0.5)
+			return variance / float64(validCount)
 		}
 		var variance float64
 		var validCount float64
@@ -266,11 +278,10 @@ func (s *Series) Std() float64 {
 			variance += p[0]
 			validCount += p[1]
 		}
-		return math.Pow(variance/validCount, 0.5)
+		return variance / validCount
 
 	case options.Int64:
 		data := ensureFloatFromNumerics(s.Vals())
-		mean := s.Mean()
 		var variance float64
 		var counter int
 		for i, d := range data {
@@ -279,12 +290,58 @@ func (s *Series) Std() float64 {
 				counter++
 			}
 		}
-		return math.Pow(variance/float64(counter), 0.5)
+		return variance / float64(counter)
+	default:
+		return math.NaN()
+	}
+}
+
+// Prod returns the product of non-null float64 or int64 Series values, or 1 if there are none. If inapplicable, defaults to math.Nan().
+func (s *Series) Prod() float64 {
+	switch s.datatype {
+	case options.Float64, options.Int64:
+		prod := 1.0
+		for _, d := range ensureFloatFromNumerics(s.validVals()) {
+			prod *= d
+		}
+		return prod
+	default:
+		return math.NaN()
+	}
+}
+
+// Quantile returns the value below which a fraction q of the non-null values fall,
+// interpolated linearly between the closest ranks. q must be between 0 and 1.
+// Applies to float64 and int64. If inapplicable, defaults to math.Nan().
+func (s *Series) Quantile(q float64) float64 {
+	if q < 0 || q > 1 {
+		return math.NaN()
+	}
+	switch s.datatype {
+	case options.Float64, options.Int64:
+		data := ensureFloatFromNumerics(s.validVals())
+		c := make([]float64, len(data))
+		copy(c, data)
+		sort.Float64s(c)
+		return quantile(c, q)
 	default:
 		return math.NaN()
 	}
 }
 
+// quantile interpolates the value at fraction q of sorted data, or returns NaN if data is empty.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if frac := pos - float64(lower); frac > 0 {
+		return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+	}
+	return sorted[lower]
+}
+
 // isolated as its own function for us in Median() and Quartile()
 func median(data []float64) float64 {
 	if len(data) == 0 {
@@ -242,3 +242,57 @@ func TestMath_unsupported_other(t *testing.T) {
 		t.Errorf("quartiles() of len < 4 returned %v, want NaN, median, NaN", gotQuartiles)
 	}
 }
+
+func TestMath_reductions(t *testing.T) {
+	var tests = []struct {
+		name         string
+		s            *Series
+		wantVar      float64
+		wantProd     float64
+		wantQuantile float64
+		wantCount    int
+		wantNUnique  int
+		wantMode     interface{}
+	}{
+		{"float with null", MustNew([]float64{math.NaN(), 2, 4, 4}), 8.0 / 9, 32, 3, 3, 2, 4.0},
+		{"int with null", MustNew([]interface{}{"", 1, 2, 2, 5}, Config{DataType: options.Int64}), 2.25, 20, 1.75, 4, 3, int64(2)},
+		{"string", MustNew([]string{"foo", "bar", "bar"}), math.NaN(), math.NaN(), math.NaN(), 3, 2, "bar"},
+		{"empty", MustNew([]float64{}), math.NaN(), 1, math.NaN(), 0, 0, nil},
+		{"interface of mixed types", MustNew([]interface{}{int64(1), "1", int64(1)}, Config{DataType: options.Interface}),
+			math.NaN(), math.NaN(), math.NaN(), 3, 2, int64(1)},
+	}
+	equalFloat := func(a, b float64) bool {
+		return (math.IsNaN(a) && math.IsNaN(b)) || math.Abs(a-b) < 1e-9
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Var(); !equalFloat(got, tt.wantVar) {
+				t.Errorf("Var() returned %v, want %v", got, tt.wantVar)
+			}
+			options.SetAsync(false)
+			gotSync := tt.s.Var()
+			options.RestoreDefaults()
+			if !equalFloat(gotSync, tt.wantVar) {
+				t.Errorf("Var() synchronous returned %v, want %v", gotSync, tt.wantVar)
+			}
+			if got := tt.s.Prod(); !equalFloat(got, tt.wantProd) {
+				t.Errorf("Prod() returned %v, want %v", got, tt.wantProd)
+			}
+			if got := tt.s.Quantile(0.25); !equalFloat(got, tt.wantQuantile) {
+				t.Errorf("Quantile(0.25) returned %v, want %v", got, tt.wantQuantile)
+			}
+			if got := tt.s.Count(); got != tt.wantCount {
+				t.Errorf("Count() returned %v, want %v", got, tt.wantCount)
+			}
+			if got := tt.s.NUnique(); got != tt.wantNUnique {
+				t.Errorf("NUnique() returned %v, want %v", got, tt.wantNUnique)
+			}
+			if got := tt.s.Mode(); got != tt.wantMode {
+				t.Errorf("Mode() returned %v (%T), want %v (%T)", got, got, tt.wantMode, tt.wantMode)
+			}
+		})
+	}
+	if got := MustNew([]int{1, 2}).Quantile(1.5); !math.IsNaN(got) {
+		t.Errorf("Quantile(1.5) returned %v, want NaN", got)
+	}
+}
@@ -3,9 +3,8 @@ package series
 import (
 	"fmt"
 	"log"
-	"reflect"
 
-	"github.com/ptiger10/pd/internal/index"
+	"github.com/ptiger10/pd/internal/values"
 	"github.com/ptiger10/pd/options"
 )
 
@@ -33,37 +32,41 @@ func (s *Series) Join(s2 *Series) (*Series, error) {
 	return s, err
 }
 
-// LookupSeries performs a vlookup of each values in one Series against another Series.
-func (s *Series) LookupSeries(s2 *Series) *Series {
-	if s2.index.NumLevels() != s.NumLevels() {
+// LookupSeries performs a vlookup of each index label in s against the index labels of s2
+// and returns a new Series with the index of s and the matching values from s2.
+// Labels are matched by type and value across all index levels.
+// Labels that are not in s2 are filled with config.Default if supplied, or null otherwise.
+func (s *Series) LookupSeries(s2 *Series, config ...LookupConfig) *Series {
+	if len(config) > 1 {
 		if options.GetLogWarnings() {
-			log.Printf("Series.LookupSeries(): s2 must have same number of index levels as s (%d != %d)\n", s2.index.NumLevels(), s.NumLevels())
+			log.Printf("Series.LookupSeries(): can supply at most one LookupConfig (%d > 1)\n", len(config))
 		}
 		return newEmptySeries()
 	}
-
-	matchShallow := func(s *Series, idx index.Elements) int {
-		for i := 0; i < s.Len(); i++ {
-			if reflect.DeepEqual(idx, s.index.Elements(i)) {
-				return i
-			}
+	var cfg LookupConfig
+	if len(config) == 1 {
+		cfg = config[0]
+	}
+	positions, err := s.index.LookupPositions(s2.index, cfg.Duplicates)
+	if err != nil {
+		if options.GetLogWarnings() {
+			log.Printf("Series.LookupSeries(): %v\n", err)
 		}
-		return -1
+		return newEmptySeries()
 	}
-
-	vals := make([]interface{}, 0)
-	for i := 0; i < s.Len(); i++ {
-		elems := s.index.Elements(i)
-		pos := matchShallow(s2, elems)
-		if pos != -1 {
-			vals = append(vals, s2.At(pos))
-		} else {
-			vals = append(vals, "")
+	container := values.Container{Values: s2.values, DataType: s2.datatype}.Lookup(positions)
+	if cfg.Default != nil {
+		if _, err := values.InterfaceFactory(cfg.Default); err != nil {
+			if options.GetLogWarnings() {
+				log.Printf("Series.LookupSeries(): invalid Default: %v\n", err)
+			}
+			return newEmptySeries()
+		}
+		for i, pos := range positions {
+			if pos == -1 {
+				container.Values.Set(i, cfg.Default)
+			}
 		}
 	}
-	// ducks error because there will be no unsupported values coming from an existing series
-	ret, _ := New(vals, Config{DataType: s2.datatype})
-	ret.index = s.index
-
-	return ret
+	return FromInternalComponents(container, s.index.Copy(), s2.name)
 }
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"log"
 	"os"
-	"reflect"
 	"strings"
 	"testing"
 )
@@ -86,8 +85,10 @@ func TestSeries_Join(t *testing.T) {
 func TestSeries_LookupSeries(t *testing.T) {
 	multi := MustNew([]string{"foo", "bar"}, Config{MultiIndex: []interface{}{[]string{"baz", "qux"}, []int{1, 2}}})
 	multi2 := MustNew("corge", Config{MultiIndex: []interface{}{[]string{"baz"}, []int{1}}})
+	dupes := MustNew([]int{10, 20, 30}, Config{Index: []string{"foo", "foo", "bar"}, Name: "qux"})
 	type args struct {
-		s2 *Series
+		s2     *Series
+		config []LookupConfig
 	}
 	tests := []struct {
 		name     string
@@ -98,9 +99,27 @@ func TestSeries_LookupSeries(t *testing.T) {
 	}{
 		{name: "single", input: MustNew("foo"), args: args{s2: MustNew("bar")},
 			want: MustNew("bar"), wantFail: false},
-		{"multi", multi, args{multi2},
+		{"multi", multi, args{multi2, nil},
 			MustNew([]string{"corge", ""}, Config{MultiIndex: []interface{}{[]string{"baz", "qux"}, []int{1, 2}}}), false},
-		{"fail", MustNew("foo"), args{multi2},
+		{"default", multi, args{multi2, []LookupConfig{{Default: "waldo"}}},
+			MustNew([]string{"corge", "waldo"}, Config{MultiIndex: []interface{}{[]string{"baz", "qux"}, []int{1, 2}}}), false},
+		{"default converted to s2 type", MustNew([]int{1, 2}, Config{Index: []string{"foo", "baz"}}), args{dupes, []LookupConfig{{Default: "0"}}},
+			MustNew([]int{10, 0}, Config{Index: []string{"foo", "baz"}, Name: "qux"}), false},
+		{"duplicates: first", MustNew([]int{1, 2}, Config{Index: []string{"foo", "bar"}}), args{dupes, nil},
+			MustNew([]int{10, 30}, Config{Index: []string{"foo", "bar"}, Name: "qux"}), false},
+		{"duplicates: last", MustNew([]int{1, 2}, Config{Index: []string{"foo", "bar"}}), args{dupes, []LookupConfig{{Duplicates: "last"}}},
+			MustNew([]int{20, 30}, Config{Index: []string{"foo", "bar"}, Name: "qux"}), false},
+		{"labels of different types do not match", MustNew("foo", Config{Index: "1"}), args{MustNew("bar", Config{Index: 1}), []LookupConfig{{Default: "baz"}}},
+			MustNew("baz", Config{Index: "1"}), false},
+		{"fail: duplicates: error", MustNew([]int{1, 2}, Config{Index: []string{"foo", "bar"}}), args{dupes, []LookupConfig{{Duplicates: "error"}}},
+			newEmptySeries(), true},
+		{"fail: invalid duplicates", MustNew("foo"), args{MustNew("bar"), []LookupConfig{{Duplicates: "corge"}}},
+			newEmptySeries(), true},
+		{"fail: invalid default", MustNew("foo"), args{MustNew("bar"), []LookupConfig{{Default: complex64(1)}}},
+			newEmptySeries(), true},
+		{"fail: multiple configs", MustNew("foo"), args{MustNew("bar"), []LookupConfig{{}, {}}},
+			newEmptySeries(), true},
+		{"fail: levels", MustNew("foo"), args{multi2, nil},
 			newEmptySeries(), true},
 	}
 	for _, tt := range tests {
@@ -109,8 +128,8 @@ func TestSeries_LookupSeries(t *testing.T) {
 			log.SetOutput(&buf)
 			defer log.SetOutput(os.Stderr)
 
-			if got := tt.input.LookupSeries(tt.args.s2); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Series.LookupSeries() = %v, want %v", got.index, tt.want.index)
+			if got := tt.input.LookupSeries(tt.args.s2, tt.args.config...); !Equal(got, tt.want) {
+				t.Errorf("Series.LookupSeries() = %v, want %v", got, tt.want)
 			}
 			if tt.wantFail {
 				if buf.String() == "" {
@@ -5,6 +5,7 @@ import (
 	"log"
 	"sort"
 
+	"github.com/ptiger10/pd/internal/index"
 	"github.com/ptiger10/pd/internal/values"
 
 	"github.com/ptiger10/pd/options"
@@ -87,6 +88,22 @@ func (s *Series) Subset(rowPositions []int) (*Series, error) {
 	return s, nil
 }
 
+// subset returns a new Series with the values and index labels at rowPositions, which must be valid,
+// without copying the rest of the Series.
+func (s *Series) subset(rowPositions []int) *Series {
+	container := values.Container{Values: s.values.Subset(rowPositions), DataType: s.datatype}
+	return FromInternalComponents(container, s.subsetIndex(rowPositions), s.name)
+}
+
+// subsetIndex returns a new Index with the labels at rowPositions, which must be valid.
+func (s *Series) subsetIndex(rowPositions []int) index.Index {
+	levels := make([]index.Level, s.NumLevels())
+	for j, lvl := range s.index.Levels {
+		levels[j] = index.Level{Labels: lvl.Labels.Subset(rowPositions), DataType: lvl.DataType, Name: lvl.Name, NeedsRefresh: true}
+	}
+	return index.New(levels...)
+}
+
 // Insert inserts a new row into the Series immediately before the specified integer position and modifies the Series in place.
 // If the original Series is empty, replaces it with a new Series.
 func (ip InPlace) Insert(pos int, val interface{}, idxLabels ...interface{}) error {
@@ -199,7 +216,7 @@ func (ip InPlace) DropRows(rowPositions []int) error {
 func (ip InPlace) DropDuplicates() {
 	g := ip.s.GroupByIndex()
 	var toDrop []int
-	for _, group := range g.Groups() {
+	for _, group := range g.ids() {
 		// only inspect groups with at least one position
 		if positions := g.groups[group].Positions; len(positions) > 0 {
 			exists := make(map[interface{}]bool)
@@ -423,3 +440,14 @@ func (s *Series) ToInterface() *Series {
 // 		return t.Truncate(d)
 // 	})
 // }
+
+// Explode returns a new Series with one row for every element of the list-like values in s (e.g., []interface{} or []string),
+// repeating the index labels of the original row. Null values and empty lists become a single null, and other values are unchanged.
+// The DataType is re-inferred from the non-null values.
+func (s *Series) Explode() *Series {
+	if s.Len() == 0 {
+		return s.Copy()
+	}
+	container, positions := values.Container{Values: s.values, DataType: s.datatype}.Explode()
+	return FromInternalComponents(container, s.subsetIndex(positions), s.name)
+}
@@ -737,3 +737,27 @@ func TestModify_DatatypeConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestSeries_Explode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *Series
+		want  *Series
+	}{
+		{"lists", MustNew([]interface{}{[]string{"foo", "bar"}, []string{"baz"}}, Config{Index: []int{1, 2}, Name: "qux"}),
+			MustNew([]string{"foo", "bar", "baz"}, Config{Index: []int{1, 1, 2}, Name: "qux"})},
+		{"empty list and null", MustNew([]interface{}{[]interface{}{"foo"}, []interface{}{}, ""}, Config{DataType: options.Interface}),
+			MustNew([]string{"foo", "", ""}, Config{Index: []int{0, 1, 2}})},
+		{"mixed list and scalar", MustNew([]interface{}{[]int{1, 2}, 3}, Config{DataType: options.Interface}),
+			MustNew([]int{1, 2, 3}, Config{Index: []int{0, 0, 1}})},
+		{"not list-like", MustNew([]string{"foo"}), MustNew([]string{"foo"}, Config{Index: []int{0}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.Explode()
+			if !Equal(got, tt.want) {
+				t.Errorf("Series.Explode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -77,10 +77,47 @@ type Config struct {
 	Manual          bool
 }
 
+// A LookupConfig customizes a lookup of values by index labels.
+// Duplicates determines which value is returned when a label appears more than once in the lookup Series: "first" (default), "last", or "error".
+// Default is the value returned when a label is missing from the lookup Series. If Default is nil, a null value is returned instead.
+type LookupConfig struct {
+	Duplicates string
+	Default    interface{}
+}
+
+// CutOptions customizes the binning of values by Cut and QCut.
+// Labels name the intervals between consecutive edges. By default, intervals are labeled by their edges (e.g., "[0, 18)").
+// If Right is true, intervals include their right edge instead of their left edge (e.g., "(0, 18]").
+// If IncludeLowest is true and Right is true, the first interval also includes its left edge (e.g., "[0, 18]").
+type CutOptions struct {
+	Labels        []string
+	Right         bool
+	IncludeLowest bool
+}
+
 // A Grouping returns a collection of index labels with mutually exclusive integer positions.
 type Grouping struct {
 	s      *Series
 	groups map[string]*group
+	// grouped Series, before any index levels are dropped
+	source *Series
+	// group ids, in order of first appearance
+	order []string
+}
+
+// A GroupKey identifies a group in a Grouping by its typed label in each grouped index level, in level order.
+// Groups are distinct if any label differs in value or type, even if their String() labels are the same.
+type GroupKey struct {
+	Labels []interface{}
+}
+
+// String returns the group label that identifies the group in Grouping.Groups() and Grouping.Group().
+func (key GroupKey) String() string {
+	labels := make([]string, len(key.Labels))
+	for j, label := range key.Labels {
+		labels[j] = fmt.Sprint(label)
+	}
+	return strings.Join(labels, values.GetMultiColNameSeparator())
 }
 
 func (g Grouping) String() string {